@@ -1,41 +1,33 @@
 package commands
 
 import (
-	"fmt"
-	"strconv"
-
+	"github.com/dkrichards86/gotodo/internal/gotodo"
 	"github.com/spf13/cobra"
 )
 
 var addContextCmd = &cobra.Command{
-	Use:   "addcontext [TODO ID] [CONTEXT]",
-	Short: "Add a new context to a todo",
-	Args:  cobra.ExactArgs(2),
+	Use:   "addcontext [TODO ID...] [CONTEXT]",
+	Short: "Add a new context to todos",
+	Args:  cobra.MinimumNArgs(1),
 	RunE:  addContextFunc,
 }
 
 func init() {
 	rootCmd.AddCommand(addContextCmd)
+	addSelectionFlags(addContextCmd)
 }
 
 func addContextFunc(cmd *cobra.Command, args []string) error {
 	var err error
 	todoManager := getManager()
 
-	todoNum := args[0]
-	todoID, err := strconv.Atoi(todoNum)
-	if err != nil {
-		return err
-	}
-
-	context := args[1]
-	err = todoManager.AddContext(todoID, context)
-
+	context := args[len(args)-1]
+	todoIDs, err := selectTodos(cmd, todoManager, args[:len(args)-1], gotodo.ListAll)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Added context \"%s\" to Todo ID %d\n", context, todoID)
+	results, err := todoManager.AddContextAll(todoIDs, context)
 
-	return nil
+	return printResults(results, err, "Added context \"%s\" to Todo ID %d", context)
 }
@@ -1,41 +1,33 @@
 package commands
 
 import (
-	"fmt"
-	"strconv"
-
+	"github.com/dkrichards86/gotodo/internal/gotodo"
 	"github.com/spf13/cobra"
 )
 
 var addProjectCmd = &cobra.Command{
-	Use:   "addproject [TODO ID] [PROJECT]",
-	Short: "Add a new project to a todo",
-	Args:  cobra.ExactArgs(2),
+	Use:   "addproject [TODO ID...] [PROJECT]",
+	Short: "Add a new project to todos",
+	Args:  cobra.MinimumNArgs(1),
 	RunE:  addProjectFunc,
 }
 
 func init() {
 	rootCmd.AddCommand(addProjectCmd)
+	addSelectionFlags(addProjectCmd)
 }
 
 func addProjectFunc(cmd *cobra.Command, args []string) error {
 	var err error
 	todoManager := getManager()
 
-	todoNum := args[0]
-	todoID, err := strconv.Atoi(todoNum)
-	if err != nil {
-		return err
-	}
-
-	project := args[1]
-	err = todoManager.AddProject(todoID, project)
-
+	project := args[len(args)-1]
+	todoIDs, err := selectTodos(cmd, todoManager, args[:len(args)-1], gotodo.ListAll)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Added project \"%s\" to Todo ID %d\n", project, todoID)
+	results, err := todoManager.AddProjectAll(todoIDs, project)
 
-	return nil
+	return printResults(results, err, "Added project \"%s\" to Todo ID %d", project)
 }
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "Shows pending todos grouped by when they are due",
+	Long: `Shows pending todos grouped by when they are due: overdue, today, tomorrow, the rest of this
+week, later, and todos with no due date. Weeks end on Sunday. Within a group, todos are ordered by
+due date and then by priority. Takes the same filters as list.`,
+	Args: cobra.NoArgs,
+	RunE: agendaFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(agendaCmd)
+
+	agendaCmd.Flags().Bool("show-future", false, "show todos with a threshold date in the future")
+	agendaCmd.Flags().Bool("show-blocked", false, "show todos waiting for others to be completed")
+	addFilterFlags(agendaCmd)
+}
+
+func agendaFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	listFilter, err := getListFilter(cmd, gotodo.ListPending)
+	if err != nil {
+		return err
+	}
+
+	showFutureFlag, err := cmd.Flags().GetBool("show-future")
+	if err != nil {
+		return err
+	}
+	showBlockedFlag, err := cmd.Flags().GetBool("show-blocked")
+	if err != nil {
+		return err
+	}
+
+	listFilter.ShowFuture = showFutureFlag
+	listFilter.ShowBlocked = showBlockedFlag
+
+	items, err := todoManager.List(listFilter)
+	if err != nil {
+		return err
+	}
+
+	// Agenda keeps this order among todos due the same day
+	sort.Sort(gotodo.ByEffectivePriority{
+		Todos:    items,
+		Priority: todoManager.EffectivePriority,
+		Overdue:  todoManager.Overdue,
+	})
+
+	format, err := getOutputFormat()
+	if err != nil {
+		return err
+	}
+
+	header := []string{"When", "ID", "Due", "Todo"}
+	data := make([][]string, 0, len(items))
+	for _, group := range gotodo.Agenda(items, time.Now()) {
+		for i, todo := range group.Todos {
+			// A table only names the group on its first row
+			when := group.Name
+			if format == "table" && i > 0 {
+				when = ""
+			}
+
+			due := ""
+			if todo.DueDate.Valid {
+				due = todo.DueDate.Time.Format("Mon " + gotodo.TimeFormat)
+			}
+
+			data = append(data, []string{when, fmt.Sprintf("%d", todo.TodoID), due, todo.String()})
+		}
+	}
+
+	return printTable(header, data, "No todos to display.")
+}
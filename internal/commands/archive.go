@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Moves completed todos to the archive",
+	Args:  cobra.NoArgs,
+	RunE:  archiveFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+}
+
+func archiveFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	archived, err := todoManager.Archive()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Archived %d completed todos\n", archived)
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// backupTimeFormat timestamps backup file names, so they sort by the time they were made
+const backupTimeFormat = "20060102-150405"
+
+var backupCmd = &cobra.Command{
+	Use:   "backup [DIR]",
+	Short: "Save a copy of every bucket of todos",
+	Long: `Save a copy of every bucket of todos, including the archive, to a timestamped text file in DIR,
+or in backup_dir when DIR isn't given. Use "-" as DIR to write the backup to standard output.
+Each todo is written on its own line after its ID, so a backup can be read without gotodo.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: backupFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	viper.SetDefault("backup_dir", ".")
+}
+
+func backupFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	dir := viper.GetString("backup_dir")
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	backup, err := todoManager.Backup()
+	if err != nil {
+		return err
+	}
+
+	if dir == "-" {
+		return backup.Write(os.Stdout)
+	}
+
+	dir, err = homedir.Expand(dir)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "gotodo-backup-"+backup.Created.Format(backupTimeFormat)+".txt")
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = backup.Write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, bucket := range backup.Buckets {
+		count += len(bucket.Todos)
+	}
+	fmt.Printf("Backed up %d buckets with %d todos to %s\n", len(backup.Buckets), count, path)
+
+	return nil
+}
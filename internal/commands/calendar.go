@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// calendarMonthFormat is how a month is written on the command line
+const calendarMonthFormat = "2006-01"
+
+// calendarTitleWidth is the most characters of a todo shown in a day of the calendar with --titles
+const calendarTitleWidth = 16
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar [MONTH]",
+	Short: "Shows a month of todos by their due and threshold dates",
+	Long: `Shows a month as a grid of weeks, with the number of pending todos due on each day and the
+number starting on it, by their t: threshold date. Use --titles to show the todos themselves, with
+todos starting that day marked "t:".
+
+MONTH is the current month by default, a month like 2020-05, or the month of a date like
+next-month or +2m.
+Takes the same filters as list, and --all to include completed todos.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: calendarFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(calendarCmd)
+
+	calendarCmd.Flags().Bool("all", false, "show pending and completed todos")
+	calendarCmd.Flags().Bool("titles", false, "show the todos on each day instead of counting them")
+	calendarCmd.Flags().Bool("show-blocked", false, "show todos waiting for others to be completed")
+	addFilterFlags(calendarCmd)
+}
+
+// shorten cuts text down to width characters, ending it with "…" when it was cut
+func shorten(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+
+	return string(runes[:width-1]) + "…"
+}
+
+// calendarTitle is the description of a todo without its due: and t: dates, which the calendar
+// already shows
+func calendarTitle(todo *gotodo.Todo) string {
+	words := make([]string, 0)
+	for _, word := range strings.Fields(todo.Description) {
+		if !strings.HasPrefix(word, "due:") && !strings.HasPrefix(word, "t:") {
+			words = append(words, word)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// calendarCell describes a day of the calendar, one line per count or todo
+func calendarCell(day gotodo.CalendarDay, today time.Time, titles bool) string {
+	lines := []string{fmt.Sprintf("%d", day.Date.Day())}
+	if day.Date.Equal(today) {
+		lines[0] += " *"
+	}
+
+	if !titles {
+		if len(day.Due) > 0 {
+			lines = append(lines, fmt.Sprintf("%d due", len(day.Due)))
+		}
+		if len(day.Starts) > 0 {
+			lines = append(lines, fmt.Sprintf("%d start", len(day.Starts)))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for _, todo := range day.Due {
+		lines = append(lines, shorten(fmt.Sprintf("%d %s", todo.TodoID, calendarTitle(todo)), calendarTitleWidth))
+	}
+	for _, todo := range day.Starts {
+		lines = append(lines, shorten(fmt.Sprintf("t:%d %s", todo.TodoID, calendarTitle(todo)), calendarTitleWidth))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// todoIDs lists the IDs of todos, separated by commas
+func todoIDs(items gotodo.TodoList) string {
+	ids := make([]string, len(items))
+	for i, todo := range items {
+		ids[i] = fmt.Sprintf("%d", todo.TodoID)
+	}
+
+	return strings.Join(ids, ", ")
+}
+
+func calendarFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	now := time.Now()
+	month := now
+	if len(args) > 0 {
+		month, err = time.Parse(calendarMonthFormat, args[0])
+		if err != nil {
+			month, err = gotodo.ParseDate(args[0], now)
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid month \"%s\", use YYYY-MM or a date like next-month or +2m", args[0])
+		}
+	}
+
+	allFlag, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+	status := gotodo.ListPending
+	if allFlag {
+		status = gotodo.ListAll
+	}
+
+	titlesFlag, err := cmd.Flags().GetBool("titles")
+	if err != nil {
+		return err
+	}
+
+	listFilter, err := getListFilter(cmd, status)
+	if err != nil {
+		return err
+	}
+
+	showBlockedFlag, err := cmd.Flags().GetBool("show-blocked")
+	if err != nil {
+		return err
+	}
+
+	// Todos starting later in the month are the point of a calendar, so future ones are included
+	listFilter.ShowFuture = true
+	listFilter.ShowBlocked = showBlockedFlag
+
+	items, err := todoManager.List(listFilter)
+	if err != nil {
+		return err
+	}
+
+	days := gotodo.Calendar(items, month)
+
+	format, err := getOutputFormat()
+	if err != nil {
+		return err
+	}
+
+	// todo.txt output lists the todos shown in the month, by day, once each
+	if format == "todotxt" {
+		seen := make(map[int]bool)
+		for _, day := range days {
+			for _, todos := range []gotodo.TodoList{day.Due, day.Starts} {
+				for _, todo := range todos {
+					if !seen[todo.TodoID] {
+						seen[todo.TodoID] = true
+						fmt.Println(todo.String())
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	// Structured output lists the todos due and starting on every day by ID
+	if format != "table" {
+		data := make([][]string, len(days))
+		for i, day := range days {
+			data[i] = []string{day.Date.Format(gotodo.TimeFormat), todoIDs(day.Due), todoIDs(day.Starts)}
+		}
+		return printTable([]string{"Date", "Due", "Starts"}, data, "")
+	}
+
+	// Weeks run from Monday to Sunday, with blank days before the first of the month
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	weeks := make([][]string, 0)
+	week := make([]string, (int(days[0].Date.Weekday())+6)%7)
+	for _, day := range days {
+		week = append(week, calendarCell(day, today, titlesFlag))
+		if len(week) == 7 {
+			weeks = append(weeks, week)
+			week = make([]string, 0, 7)
+		}
+	}
+	if len(week) > 0 {
+		weeks = append(weeks, append(week, make([]string, 7-len(week))...))
+	}
+
+	fmt.Println(days[0].Date.Format("January 2006"))
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.AppendBulk(weeks)
+	table.Render()
+
+	return nil
+}
@@ -1,41 +1,41 @@
 package commands
 
 import (
-	"fmt"
-	"strconv"
-
+	"github.com/dkrichards86/gotodo/internal/gotodo"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var completeCmd = &cobra.Command{
-	Use:     "complete [TODO ID]",
-	Short:   "marks a todo as complete",
+	Use:     "complete [TODO ID...]",
+	Short:   "marks todos as complete",
 	Aliases: []string{"do"},
-	Args:    cobra.ExactArgs(1),
 	RunE:    completeFunc,
 }
 
 func init() {
 	rootCmd.AddCommand(completeCmd)
+	addSelectionFlags(completeCmd)
+
+	completeCmd.Flags().Bool("cascade", false, "also complete the pending subtasks of each todo")
+	viper.BindPFlag("cascade_complete", completeCmd.Flags().Lookup("cascade"))
 }
 
 func completeFunc(cmd *cobra.Command, args []string) error {
 	var err error
 	todoManager := getManager()
 
-	todoNum := args[0]
-	todoID, err := strconv.Atoi(todoNum)
+	todoIDs, err := selectTodos(cmd, todoManager, args, gotodo.ListPending)
 	if err != nil {
 		return err
 	}
 
-	err = todoManager.Complete(todoID)
-
-	if err != nil {
-		return err
+	var results []gotodo.BulkResult
+	if viper.GetBool("cascade_complete") {
+		results, err = todoManager.CompleteCascade(todoIDs)
+	} else {
+		results, err = todoManager.CompleteAll(todoIDs)
 	}
 
-	fmt.Printf("Completed Todo ID %d\n", todoID)
-
-	return nil
+	return printResults(results, err, "Completed Todo ID %d")
 }
@@ -1,41 +1,32 @@
 package commands
 
 import (
-	"fmt"
-	"strconv"
-
+	"github.com/dkrichards86/gotodo/internal/gotodo"
 	"github.com/spf13/cobra"
 )
 
 var depriCmd = &cobra.Command{
-	Use:     "deprioritize [TODO ID]",
-	Short:   "Removes the priority from a todo",
+	Use:     "deprioritize [TODO ID...]",
+	Short:   "Removes the priority from todos",
 	Aliases: []string{"depri"},
-	Args:    cobra.ExactArgs(1),
 	RunE:    depriFunc,
 }
 
 func init() {
 	rootCmd.AddCommand(depriCmd)
+	addSelectionFlags(depriCmd)
 }
 
 func depriFunc(cmd *cobra.Command, args []string) error {
 	var err error
 	todoManager := getManager()
 
-	todoNum := args[0]
-	todoID, err := strconv.Atoi(todoNum)
-	if err != nil {
-		return err
-	}
-
-	err = todoManager.Deprioritize(todoID)
-
+	todoIDs, err := selectTodos(cmd, todoManager, args, gotodo.ListAll)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Removed priority for Todo ID %d\n", todoID)
+	results, err := todoManager.DeprioritizeAll(todoIDs)
 
-	return nil
+	return printResults(results, err, "Removed priority for Todo ID %d")
 }
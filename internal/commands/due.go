@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+var dueCmd = &cobra.Command{
+	Use:   "due [TODO ID] [WHEN]",
+	Short: "Sets the due date of a todo, e.g. to tomorrow, fri, +3d or eom, or clears it with none",
+	Long: `Sets the due date of a todo and its due: attribute. WHEN is a date like 2020-05-01, or one of:
+
+  today, tomorrow, yesterday
+  a weekday such as fri or friday, for the next one after today
+  +3d, +2w, +1m, +1y or +5b, counting days, weeks, months, years or business days from today
+  eow, eom, eoy for the last day of this week, month or year
+  next-week, next-month, next-year for the first day of the next week, month or year
+
+"none" clears the due date. The same dates can be written in due: when adding or editing a todo,
+and are saved as YYYY-MM-DD.`,
+	Args: cobra.ExactArgs(2),
+	RunE: dueFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(dueCmd)
+}
+
+func dueFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	todoNum := args[0]
+	todoID, err := strconv.Atoi(todoNum)
+	if err != nil {
+		return err
+	}
+
+	when := args[1]
+	if when == "none" {
+		err = todoManager.ClearDue(todoID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Cleared the due date of Todo ID %d\n", todoID)
+		return nil
+	}
+
+	date, err := todoManager.SetDue(todoID, when)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Todo ID %d is due %s\n", todoID, date.Format(gotodo.TimeFormat))
+
+	return nil
+}
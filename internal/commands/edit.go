@@ -1,24 +1,41 @@
 package commands
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
 	"strconv"
+	"strings"
 
+	"github.com/dkrichards86/gotodo/internal/gotodo"
 	"github.com/spf13/cobra"
 )
 
 var editCmd = &cobra.Command{
 	Use:   "edit [TODO ID] [TODO]",
-	Short: "Edit a new todo",
-	Args:  cobra.ExactArgs(2),
-	RunE:  editFunc,
+	Short: "Edit a todo, or several in $EDITOR",
+	Long: `Edit a todo by replacing it with TODO. Without TODO, the todo is opened in $EDITOR.
+
+With --all, --query or --project, every todo selected is opened in $EDITOR one per line, after
+its ID in brackets. Change a todo by editing its line, remove it by deleting the line, and add
+one with a new line without an ID. The changes are checked before any of them are saved, and
+removing todos asks for confirmation unless --yes is given. Saving a list without any todos
+leaves them all unchanged.`,
+	Args: cobra.RangeArgs(0, 2),
+	RunE: editFunc,
 }
 
 func init() {
 	rootCmd.AddCommand(editCmd)
 	editCmd.Flags().Bool("append", false, "add message to the end of a tod")
 	editCmd.Flags().Bool("prepend", false, "add message to the front of a todo")
+	editCmd.Flags().Bool("all", false, "open every todo in $EDITOR")
+	editCmd.Flags().Bool("yes", false, "remove todos deleted from the editor without asking")
+	addSelectionFlags(editCmd)
 }
 
 func editFunc(cmd *cobra.Command, args []string) error {
@@ -37,12 +54,34 @@ func editFunc(cmd *cobra.Command, args []string) error {
 		return errors.New("Can't append and prepend at the same time")
 	}
 
+	allFlag, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+	if allFlag || cmd.Flags().Changed("query") || cmd.Flags().Changed("project") {
+		if len(args) > 0 || appendFlag || prependFlag {
+			return errors.New("Can't edit todo IDs or append and prepend when editing many todos")
+		}
+		return editListFunc(cmd, todoManager, allFlag)
+	}
+
+	if len(args) == 0 {
+		return errors.New("requires a todo ID, --all, --query or --project")
+	}
+
 	todoNum := args[0]
 	todoID, err := strconv.Atoi(todoNum)
 	if err != nil {
 		return err
 	}
 
+	if len(args) == 1 {
+		if appendFlag || prependFlag {
+			return errors.New("requires a message to append or prepend")
+		}
+		return editOneFunc(todoManager, todoID)
+	}
+
 	fn := todoManager.Update
 	if appendFlag {
 		fn = todoManager.Append
@@ -61,3 +100,194 @@ func editFunc(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// editOneFunc opens a single todo in the editor and saves it if it changed
+func editOneFunc(todoManager *gotodo.TodoManager, todoID int) error {
+	todo, err := todoManager.Storage.Get(todoID)
+	if err != nil {
+		return err
+	}
+
+	edited, err := runEditor(todo.String() + "\n")
+	if err != nil {
+		return err
+	}
+
+	// Editors strip trailing whitespace, so it is ignored on both sides
+	lines := make([]string, 0)
+	for _, line := range strings.Split(edited, "\n") {
+		if line = strings.TrimRight(line, " \t\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	switch {
+	case len(lines) == 0:
+		return fmt.Errorf("Todo is empty, leaving Todo ID %d unchanged", todoID)
+	case len(lines) > 1:
+		return fmt.Errorf("Todo must be a single line, leaving Todo ID %d unchanged", todoID)
+	case lines[0] == strings.TrimRight(todo.String(), " \t\r"):
+		fmt.Printf("Todo ID %d is unchanged\n", todoID)
+		return nil
+	}
+
+	if err := gotodo.ValidateTodo(lines[0]); err != nil {
+		return err
+	}
+
+	err = todoManager.Update(todoID, lines[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated Todo ID %d\n", todoID)
+
+	return nil
+}
+
+// editListFunc opens the selected todos in the editor and applies the changes made to them
+func editListFunc(cmd *cobra.Command, todoManager *gotodo.TodoManager, all bool) error {
+	items, err := todoManager.List(gotodo.TodoListFilter{Status: gotodo.ListAll, ShowFuture: true, ShowBlocked: true})
+	if err != nil {
+		return err
+	}
+
+	if !all {
+		todoIDs, err := selectTodos(cmd, todoManager, nil, gotodo.ListAll)
+		if err != nil {
+			return err
+		}
+
+		selected := make(map[int]bool)
+		for _, todoID := range todoIDs {
+			selected[todoID] = true
+		}
+
+		matched := make(gotodo.TodoList, 0, len(todoIDs))
+		for _, todo := range items {
+			if selected[todo.TodoID] {
+				matched = append(matched, todo)
+			}
+		}
+		items = matched
+	}
+
+	edited, err := runEditor(gotodo.FormatEditList(items))
+	if err != nil {
+		return err
+	}
+
+	edits, err := gotodo.ParseEditList(items, edited)
+	if err != nil {
+		return err
+	}
+
+	if len(edits) == 0 {
+		fmt.Println("No todos changed")
+		return nil
+	}
+
+	yesFlag, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return err
+	}
+
+	// Deleted lines remove todos, so make sure that was meant
+	byID := make(map[int]*gotodo.Todo)
+	for _, todo := range items {
+		byID[todo.TodoID] = todo
+	}
+	removed := make([]string, 0)
+	for _, edit := range edits {
+		if edit.TodoID != 0 && edit.Todo == "" {
+			removed = append(removed, fmt.Sprintf("  [%d] %s", edit.TodoID, byID[edit.TodoID].String()))
+		}
+	}
+	if len(removed) > 0 && !yesFlag {
+		fmt.Printf("This removes %d todos:\n%s\n", len(removed), strings.Join(removed, "\n"))
+		ok, err := confirm("Remove them?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("No todos changed")
+			return nil
+		}
+	}
+
+	results, err := todoManager.ApplyEdits(edits)
+	if err != nil {
+		return printResults(results, err, "")
+	}
+
+	for i, result := range results {
+		switch {
+		case edits[i].TodoID == 0:
+			fmt.Printf("Added Todo ID %d\n", result.TodoID)
+		case edits[i].Todo == "":
+			fmt.Printf("Removed Todo ID %d\n", result.TodoID)
+		default:
+			fmt.Printf("Updated Todo ID %d\n", result.TodoID)
+		}
+	}
+
+	return nil
+}
+
+// confirm asks a yes or no question on the terminal, taking anything but yes as no
+func confirm(question string) (bool, error) {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// getEditor returns the command line of the user's editor, from $VISUAL or $EDITOR
+func getEditor() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(name)); len(editor) > 0 {
+			return editor
+		}
+	}
+
+	return []string{"vi"}
+}
+
+// runEditor opens text in the user's editor and returns it once the editor exits
+func runEditor(text string) (string, error) {
+	file, err := ioutil.TempFile("", "gotodo-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	editor := getEditor()
+	editorCmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		return "", fmt.Errorf("Editor %s failed: %s", editor[0], err)
+	}
+
+	contents, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+
+	return string(contents), nil
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var escalateCmd = &cobra.Command{
+	Use:   "escalate",
+	Short: "Saves the priority of todos raised by their due dates",
+	Args:  cobra.NoArgs,
+	RunE:  escalateFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(escalateCmd)
+}
+
+func escalateFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	if todoManager.DuePrioritizationRate <= 0 {
+		return errors.New("Due prioritization is disabled, set due_prioritization_rate to enable it")
+	}
+
+	escalated, err := todoManager.Escalate()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Escalated priority of %d todos\n", escalated)
+
+	return nil
+}
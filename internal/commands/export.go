@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write your todos as a todo.txt file",
+	Long: `Write pending and completed todos to standard output, or to --file, as a todo.txt file in
+ID order. Use --pending, --done or --archived to pick one status, for example --archived for a
+done.txt file, and --project, --context, --attribute or --query to pick todos.`,
+	Args: cobra.NoArgs,
+	RunE: exportFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().Bool("pending", false, "only export pending todos")
+	exportCmd.Flags().Bool("done", false, "only export completed todos")
+	exportCmd.Flags().Bool("archived", false, "only export archived todos")
+	addFilterFlags(exportCmd)
+	exportCmd.Flags().String("file", "", "write to a file instead of standard output")
+}
+
+func exportFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	pendingFlag, err := cmd.Flags().GetBool("pending")
+	if err != nil {
+		return err
+	}
+	doneFlag, err := cmd.Flags().GetBool("done")
+	if err != nil {
+		return err
+	}
+	archivedFlag, err := cmd.Flags().GetBool("archived")
+	if err != nil {
+		return err
+	}
+
+	status := gotodo.ListAll
+	switch {
+	case pendingFlag && doneFlag, archivedFlag && (pendingFlag || doneFlag):
+		return errors.New("Can't filter by more than one of pending, done and archived status")
+	case pendingFlag:
+		status = gotodo.ListPending
+	case doneFlag:
+		status = gotodo.ListDone
+	case archivedFlag:
+		status = gotodo.ListArchived
+	}
+
+	// Everything selected is exported, including future and blocked todos
+	listFilter, err := getListFilter(cmd, status)
+	if err != nil {
+		return err
+	}
+	listFilter.ShowFuture = true
+	listFilter.ShowBlocked = true
+
+	fileFlag, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return err
+	}
+
+	if fileFlag == "" {
+		_, err = todoManager.Export(os.Stdout, listFilter)
+		return err
+	}
+
+	file, err := os.Create(fileFlag)
+	if err != nil {
+		return err
+	}
+
+	count, err := todoManager.Export(file, listFilter)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d todos to %s\n", count, fileFlag)
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// historyTimeFormat is how journal entry times are displayed
+const historyTimeFormat = "2006-01-02 15:04:05"
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List recent changes that can be undone or redone",
+	Args:  cobra.NoArgs,
+	RunE:  historyFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().Int("limit", 20, "number of changes to show")
+}
+
+func historyFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	limitFlag, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		return err
+	}
+
+	if todoManager.Journal == nil {
+		return errors.New("No journal is configured")
+	}
+
+	entries, position, err := todoManager.Journal.History()
+	if err != nil {
+		return err
+	}
+
+	// Show the most recent changes first, including the ones that were undone
+	header := []string{"Time", "Operation", "Todo IDs", "Status"}
+	data := make([][]string, 0)
+	for i := len(entries) - 1; i >= 0 && len(data) < limitFlag; i-- {
+		entry := entries[i]
+
+		ids := make([]string, len(entry.Changes))
+		for j, change := range entry.Changes {
+			ids[j] = fmt.Sprintf("%d", change.TodoID)
+			if change.Archive {
+				ids[j] = fmt.Sprintf("archive %d", change.TodoID)
+			}
+		}
+
+		status := "applied"
+		if i >= position {
+			status = "undone"
+		}
+
+		data = append(data, []string{
+			entry.Time.Format(historyTimeFormat),
+			entry.Operation,
+			strings.Join(ids, ", "),
+			status,
+		})
+	}
+
+	return printTable(header, data, "No changes to display.")
+}
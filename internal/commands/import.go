@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [FILE]",
+	Short: "Add every todo in a todo.txt file",
+	Long: `Add every line of a todo.txt file as a todo, or of standard input when FILE is "-". Blank
+lines are skipped. Lines with problems, like a due date that can't be read, are still added as
+written and reported as warnings. The whole import can be undone at once.`,
+	Args: cobra.ExactArgs(1),
+	RunE: importFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().Bool("dry-run", false, "show what would be imported without saving it")
+	importCmd.Flags().Bool("dedupe", false, "skip todos that are already stored or archived")
+	importCmd.Flags().Bool("archive-completed", false, "add completed todos straight to the archive")
+}
+
+func importFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	dryRunFlag, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+	dedupeFlag, err := cmd.Flags().GetBool("dedupe")
+	if err != nil {
+		return err
+	}
+	archiveFlag, err := cmd.Flags().GetBool("archive-completed")
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	report, err := todoManager.Import(r,
+		gotodo.ImportDryRun(dryRunFlag),
+		gotodo.ImportDedupe(dedupeFlag),
+		gotodo.ImportArchiveCompleted(archiveFlag),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	added, archived := report.Added()
+	skipped := len(report.Results) - added
+
+	if dryRunFlag {
+		header := []string{"Line", "Todo", "Action"}
+		data := make([][]string, len(report.Results))
+		for i, result := range report.Results {
+			action := "add"
+			if result.Duplicate {
+				action = "skip duplicate"
+			} else if result.Archived {
+				action = "archive"
+			}
+			data[i] = []string{fmt.Sprintf("%d", result.Line), result.Todo, action}
+		}
+
+		err := printTable(header, data, "No todos to import.")
+		if err != nil {
+			return err
+		}
+
+		// keep json, csv and yaml output parseable
+		if format, _ := getOutputFormat(); format == "table" && len(data) > 0 {
+			fmt.Printf("Would import %d todos (%d archived), skipping %d duplicates\n", added, archived, skipped)
+		}
+		return nil
+	}
+
+	fmt.Printf("Imported %d todos (%d archived), skipped %d duplicates\n", added, archived, skipped)
+
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/dkrichards86/gotodo/internal/gotodo"
 	"github.com/spf13/cobra"
@@ -21,11 +23,50 @@ func init() {
 
 	lsCmd.Flags().Bool("done", false, "only show completed todos")
 	lsCmd.Flags().Bool("all", false, "show pending and completed todos")
+	lsCmd.Flags().Bool("archived", false, "only show archived todos")
+	lsCmd.Flags().Bool("show-future", false, "show todos with a threshold date in the future")
+	lsCmd.Flags().Bool("show-blocked", false, "show todos waiting for others to be completed")
+	lsCmd.Flags().Bool("tree", false, "show subtasks indented under their parents")
 
 	lsCmd.Flags().String("sort", "pending", "sort todos")
-	lsCmd.Flags().String("project", "", "filter todos by project")
-	lsCmd.Flags().String("context", "", "filter todos by context")
-	lsCmd.Flags().String("attribute", "", "filter todos by attribute")
+	addFilterFlags(lsCmd)
+}
+
+// addFilterFlags adds the flags list filters todos with, which other listing commands share
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("project", "", "filter todos by project")
+	cmd.Flags().String("context", "", "filter todos by context")
+	cmd.Flags().String("attribute", "", "filter todos by attribute")
+	cmd.Flags().String("query", "", "filter todos with a query, e.g. \"+gotodo and (@work or @home) and due<2020-06-01\"")
+}
+
+// getListFilter reads the flags added by addFilterFlags into a filter for todos with the given
+// status
+func getListFilter(cmd *cobra.Command, status int) (gotodo.TodoListFilter, error) {
+	projectFlag, err := cmd.Flags().GetString("project")
+	if err != nil {
+		return gotodo.TodoListFilter{}, err
+	}
+	contextFlag, err := cmd.Flags().GetString("context")
+	if err != nil {
+		return gotodo.TodoListFilter{}, err
+	}
+	attributeFlag, err := cmd.Flags().GetString("attribute")
+	if err != nil {
+		return gotodo.TodoListFilter{}, err
+	}
+	queryFlag, err := cmd.Flags().GetString("query")
+	if err != nil {
+		return gotodo.TodoListFilter{}, err
+	}
+
+	return gotodo.TodoListFilter{
+		Status:    status,
+		Project:   projectFlag,
+		Context:   contextFlag,
+		Attribute: attributeFlag,
+		Query:     queryFlag,
+	}, nil
 }
 
 func lsFunc(cmd *cobra.Command, args []string) error {
@@ -41,8 +82,15 @@ func lsFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	archivedFlag, err := cmd.Flags().GetBool("archived")
+	if err != nil {
+		return err
+	}
+
 	if allFlag && doneFlag {
 		return errors.New("Can't filter by both done and all status")
+	} else if archivedFlag && (allFlag || doneFlag) {
+		return errors.New("Can't filter by both archived and done or all status")
 	}
 
 	status := gotodo.ListPending
@@ -50,38 +98,38 @@ func lsFunc(cmd *cobra.Command, args []string) error {
 		status = gotodo.ListAll
 	} else if doneFlag {
 		status = gotodo.ListDone
+	} else if archivedFlag {
+		status = gotodo.ListArchived
 	}
 
-	projectFlag, err := cmd.Flags().GetString("project")
+	listFilter, err := getListFilter(cmd, status)
 	if err != nil {
 		return err
 	}
-	contextFlag, err := cmd.Flags().GetString("context")
+
+	showFutureFlag, err := cmd.Flags().GetBool("show-future")
 	if err != nil {
 		return err
 	}
-	attributeFlag, err := cmd.Flags().GetString("attribute")
+
+	showBlockedFlag, err := cmd.Flags().GetBool("show-blocked")
 	if err != nil {
 		return err
 	}
 
-	listFilter := gotodo.TodoListFilter{
-		Status:    status,
-		Project:   projectFlag,
-		Context:   contextFlag,
-		Attribute: attributeFlag,
+	treeFlag, err := cmd.Flags().GetBool("tree")
+	if err != nil {
+		return err
 	}
 
+	listFilter.ShowFuture = showFutureFlag
+	listFilter.ShowBlocked = showBlockedFlag
+
 	items, err := todoManager.List(listFilter)
 	if err != nil {
 		return err
 	}
 
-	if len(items) == 0 {
-		fmt.Println("No todos to display.")
-		return nil
-	}
-
 	sortFlag, err := cmd.Flags().GetString("sort")
 	if err != nil {
 		return err
@@ -93,15 +141,133 @@ func lsFunc(cmd *cobra.Command, args []string) error {
 	case "due":
 		sort.Sort(gotodo.ByDueDate(items))
 	default:
-		sort.Sort(gotodo.ByPriority(items))
+		sort.Sort(gotodo.ByEffectivePriority{
+			Todos:    items,
+			Priority: todoManager.EffectivePriority,
+			Overdue:  todoManager.Overdue,
+		})
+	}
+
+	// With --tree, subtasks follow their parents and are drawn indented under them
+	prefixes := make(map[int]string)
+	if treeFlag {
+		tree := gotodo.Tree(items)
+		items = make(gotodo.TodoList, len(tree))
+		for i, item := range tree {
+			items[i] = item.Todo
+		}
+		prefixes = treePrefixes(tree)
+	}
+
+	// Blocked todos are only listed with --show-blocked, and are marked with what they wait for
+	var links *gotodo.TodoLinks
+	if showBlockedFlag {
+		links, err = todoManager.Links()
+		if err != nil {
+			return err
+		}
+	}
+
+	blockedBy := make(map[int]string)
+	for _, todo := range items {
+		if links == nil {
+			break
+		}
+		blockers := links.BlockedBy(todo.TodoID)
+		if len(blockers) == 0 {
+			continue
+		}
+		ids := make([]string, len(blockers))
+		for i, blockerID := range blockers {
+			ids[i] = fmt.Sprintf("%d", blockerID)
+		}
+		blockedBy[todo.TodoID] = strings.Join(ids, ", ")
+	}
+
+	// Todos being worked on show how long ago they were started. Sessions belong to the IDs of
+	// live todos, which archived todos don't share.
+	elapsed := make(map[int]string)
+	if status != gotodo.ListArchived {
+		active, err := todoManager.ActiveSessions()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, todo := range items {
+			if session, ok := active[todo.TodoID]; ok {
+				elapsed[todo.TodoID] = formatDuration(session.Duration(now))
+			}
+		}
+	}
+
+	// With due prioritization, show the effective priority next to the stored one
+	showEffective := todoManager.DuePrioritizationRate > 0
+
+	header := []string{"ID"}
+	if showEffective {
+		header = append(header, "Effective")
+	}
+	header = append(header, "Todo")
+	if len(blockedBy) > 0 {
+		header = append(header, "Blocked By")
+	}
+	if len(elapsed) > 0 {
+		header = append(header, "Active")
 	}
 
-	header := []string{"ID", "Todo"}
 	data := make([][]string, len(items))
 	for i, todo := range items {
-		data[i] = []string{fmt.Sprintf("%d", todo.TodoID), todo.String()}
+		row := []string{fmt.Sprintf("%d", todo.TodoID)}
+		if showEffective {
+			effective := gotodo.PriorityString(todoManager.EffectivePriority(todo))
+			if effective != "" {
+				effective = fmt.Sprintf("(%s)", effective)
+			}
+			row = append(row, effective)
+		}
+		row = append(row, prefixes[todo.TodoID]+todo.String())
+		if len(blockedBy) > 0 {
+			row = append(row, blockedBy[todo.TodoID])
+		}
+		if len(elapsed) > 0 {
+			row = append(row, elapsed[todo.TodoID])
+		}
+		data[i] = row
+	}
+
+	return printTodos(items, header, data)
+}
+
+// treePrefixes returns the lines drawn before each todo of a tree to connect it to its parent
+func treePrefixes(tree []gotodo.TreeItem) map[int]string {
+	prefixes := make(map[int]string)
+
+	// open records, for each depth, whether the parent at that depth has more subtasks to come
+	open := make([]bool, 0)
+	for _, item := range tree {
+		if item.Depth == 0 {
+			open = open[:0]
+			continue
+		}
+
+		open = append(open[:item.Depth-1], !item.Last)
+
+		var prefix strings.Builder
+		for _, more := range open[:item.Depth-1] {
+			if more {
+				prefix.WriteString("│  ")
+			} else {
+				prefix.WriteString("   ")
+			}
+		}
+		if item.Last {
+			prefix.WriteString("└─ ")
+		} else {
+			prefix.WriteString("├─ ")
+		}
+		prefixes[item.Todo.TodoID] = prefix.String()
 	}
-	drawTable(header, data)
 
-	return nil
+	return prefixes
 }
@@ -1,8 +1,6 @@
 package commands
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 )
 
@@ -25,18 +23,5 @@ func contextsFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(items) == 0 {
-		fmt.Println("No contexts to display.")
-		return nil
-	}
-
-	header := []string{"Contexts"}
-	data := make([][]string, len(items))
-	for i, tag := range items {
-		data[i] = []string{tag}
-	}
-
-	drawTable(header, data)
-
-	return nil
+	return printStrings("Contexts", items, "No contexts to display.")
 }
@@ -1,8 +1,6 @@
 package commands
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 )
 
@@ -25,18 +23,5 @@ func projectsFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(items) == 0 {
-		fmt.Println("No projects to display.")
-		return nil
-	}
-
-	header := []string{"Projects"}
-	data := make([][]string, len(items))
-	for i, tag := range items {
-		data[i] = []string{tag}
-	}
-
-	drawTable(header, data)
-
-	return nil
+	return printStrings("Projects", items, "No projects to display.")
 }
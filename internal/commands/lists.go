@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var listsCmd = &cobra.Command{
+	Use:   "lists",
+	Short: "List your todo lists",
+	Long: `List every todo list with the number of pending, done and archived todos in it. A list is a
+bucket of the Bolt database, and the one in use is marked with a "*". Pick a list with --bucket.
+
+Lists are only supported with bolt storage.`,
+	Args: cobra.NoArgs,
+	RunE: listsFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(listsCmd)
+}
+
+func listsFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	lists, err := todoManager.Lists()
+	if err != nil {
+		return err
+	}
+
+	header := []string{"Name", "Current", "Pending", "Done", "Archived"}
+	data := make([][]string, 0, len(lists))
+	for _, list := range lists {
+		current := ""
+		if list.Current {
+			current = "*"
+		}
+		data = append(data, []string{
+			list.Name,
+			current,
+			strconv.Itoa(list.Pending),
+			strconv.Itoa(list.Done),
+			strconv.Itoa(list.Archived),
+		})
+	}
+
+	return printTable(header, data, "No lists to display.")
+}
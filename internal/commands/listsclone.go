@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var listsCloneCmd = &cobra.Command{
+	Use:   "clone FROM TO",
+	Short: "Copy a todo list and its archive to a new list",
+	Long:  `Copy a todo list and its archive to a new list. The todos keep their IDs.`,
+	Args:  cobra.ExactArgs(2),
+	RunE:  listsCloneFunc,
+}
+
+func init() {
+	listsCmd.AddCommand(listsCloneCmd)
+}
+
+func listsCloneFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	if err := todoManager.CloneList(args[0], args[1]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Cloned list %s to %s\n", args[0], args[1])
+	return nil
+}
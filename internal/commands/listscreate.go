@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var listsCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create an empty todo list",
+	Args:  cobra.ExactArgs(1),
+	RunE:  listsCreateFunc,
+}
+
+func init() {
+	listsCmd.AddCommand(listsCreateCmd)
+}
+
+func listsCreateFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	if err := todoManager.CreateList(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created list %s\n", args[0])
+	return nil
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var listsDeleteCmd = &cobra.Command{
+	Use:   "delete NAME",
+	Short: "Delete a todo list and its archive",
+	Long: `Delete a todo list and its archive. A list that still has todos is only deleted with --force.
+The list in use can't be deleted, and deleting a list can't be undone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: listsDeleteFunc,
+}
+
+func init() {
+	listsCmd.AddCommand(listsDeleteCmd)
+	listsDeleteCmd.Flags().Bool("force", false, "delete the list even if it has todos")
+}
+
+func listsDeleteFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	forceFlag, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return err
+	}
+
+	if err := todoManager.DeleteList(args[0], forceFlag); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted list %s\n", args[0])
+	return nil
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var listsRenameCmd = &cobra.Command{
+	Use:   "rename OLD NEW",
+	Short: "Rename a todo list and its archive",
+	Long: `Rename a todo list and its archive. The todos keep their IDs, and the list's journal and time
+log are renamed with it unless journal_file or time_file are configured.`,
+	Args: cobra.ExactArgs(2),
+	RunE: listsRenameFunc,
+}
+
+func init() {
+	listsCmd.AddCommand(listsRenameCmd)
+}
+
+// renameLog renames the default log of a bucket, if there is one and the new name is free
+func renameLog(oldPath string, newPath string) error {
+	oldPath, err := homedir.Expand(oldPath)
+	if err != nil {
+		return err
+	}
+	newPath, err = homedir.Expand(newPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("Can't rename %s, %s already exists", oldPath, newPath)
+	}
+
+	return os.Rename(oldPath, newPath)
+}
+
+func listsRenameFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	if err := todoManager.RenameList(args[0], args[1]); err != nil {
+		return err
+	}
+	fmt.Printf("Renamed list %s to %s\n", args[0], args[1])
+
+	oldJournal, oldTimeLog := bucketLogs(args[0])
+	newJournal, newTimeLog := bucketLogs(args[1])
+	if viper.GetString("journal_file") == "" {
+		if err := renameLog(oldJournal, newJournal); err != nil {
+			return err
+		}
+	}
+	timeLog := viper.GetString("time_file")
+	if timeLog == "" {
+		if err := renameLog(oldTimeLog, newTimeLog); err != nil {
+			return err
+		}
+		timeLog = newTimeLog
+	}
+
+	// Sessions are recorded under the name of their list, even in a time log of its own
+	if err := (&gotodo.TimeLog{Path: timeLog}).RenameList(args[0], args[1]); err != nil {
+		return err
+	}
+
+	if args[0] == viper.GetString("bucket") {
+		fmt.Printf("Use --bucket %s to keep using the list\n", args[1])
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move [TODO ID] [LIST]",
+	Short: "Moves a todo to another list",
+	Long: `Moves a todo to another list, where it gets a new ID. The todo is moved exactly as written, along
+with its tracked time, and the move can be undone from the list it came from. A todo linked to
+others with parent:, dep: or blocks: can't be moved until the links are removed.`,
+	Args: cobra.ExactArgs(2),
+	RunE: moveFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+}
+
+func moveFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	todoNum := args[0]
+	todoID, err := strconv.Atoi(todoNum)
+	if err != nil {
+		return err
+	}
+
+	// The todo's work sessions go to the time log of the other list, unless every list shares one
+	list := args[1]
+	_, timeLog := bucketLogs(list)
+	if configured := viper.GetString("time_file"); configured != "" {
+		timeLog = configured
+	}
+
+	newID, err := todoManager.Move(todoID, list, gotodo.MoveTimeLog(&gotodo.TimeLog{Path: timeLog, List: list}))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Moved Todo ID %d to %s as Todo ID %d\n", todoID, list, newID)
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// outputFormats are the values accepted by --output
+var outputFormats = []string{"table", "json", "ndjson", "csv", "yaml", "todotxt"}
+
+// getOutputFormat returns the selected output format, or an error if it isn't supported
+func getOutputFormat() (string, error) {
+	format := viper.GetString("output")
+	for _, supported := range outputFormats {
+		if format == supported {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unsupported output \"%s\", use one of %s", format, strings.Join(outputFormats, ", "))
+}
+
+func drawTable(header []string, data [][]string) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+	table.SetBorder(false)
+	table.SetAutoFormatHeaders(true)
+	table.AppendBulk(data)
+	table.Render()
+}
+
+// printJSON writes a single value as indented JSON
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// printNDJSON writes every value as JSON on its own line
+func printNDJSON(values ...interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printYAML writes a single value as YAML
+func printYAML(value interface{}) error {
+	encoded, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(encoded)
+	return err
+}
+
+// printCSV writes a header row followed by the data rows
+func printCSV(header []string, data [][]string) error {
+	writer := csv.NewWriter(os.Stdout)
+	writer.Write(header)
+	writer.WriteAll(data)
+	return writer.Error()
+}
+
+// printTodos writes todos in the selected output format. Tables are drawn from the header and
+// data built by the caller, every other format describes the todos in full.
+func printTodos(items gotodo.TodoList, header []string, data [][]string) error {
+	format, err := getOutputFormat()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		return printJSON(items)
+	case "ndjson":
+		values := make([]interface{}, len(items))
+		for i, todo := range items {
+			values[i] = todo
+		}
+		return printNDJSON(values...)
+	case "yaml":
+		return printYAML(items)
+	case "todotxt":
+		for _, todo := range items {
+			fmt.Println(todo.String())
+		}
+		return nil
+	case "csv":
+		rows := make([][]string, len(items))
+		for i, todo := range items {
+			attributes := make([]string, 0, len(todo.Attributes))
+			for key, value := range todo.Attributes {
+				attributes = append(attributes, key+":"+value)
+			}
+			sort.Strings(attributes)
+
+			rows[i] = []string{
+				fmt.Sprintf("%d", todo.TodoID),
+				fmt.Sprintf("%t", todo.Complete),
+				gotodo.PriorityString(todo.Priority),
+				todo.CompletionDate.Display(),
+				todo.CreationDate.Display(),
+				todo.DueDate.Display(),
+				todo.Description,
+				strings.Join(todo.Projects.Sorted(), " "),
+				strings.Join(todo.Contexts.Sorted(), " "),
+				strings.Join(attributes, " "),
+			}
+		}
+		return printCSV([]string{
+			"id", "complete", "priority", "completion_date", "creation_date", "due_date",
+			"description", "projects", "contexts", "attributes",
+		}, rows)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("No todos to display.")
+		return nil
+	}
+
+	drawTable(header, data)
+	return nil
+}
+
+// printStrings writes a list of values, such as project names, in the selected output format
+func printStrings(header string, items []string, empty string) error {
+	format, err := getOutputFormat()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		return printJSON(items)
+	case "ndjson":
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			values[i] = item
+		}
+		return printNDJSON(values...)
+	case "yaml":
+		return printYAML(items)
+	}
+
+	data := make([][]string, len(items))
+	for i, item := range items {
+		data[i] = []string{item}
+	}
+
+	switch format {
+	case "csv":
+		return printCSV([]string{strings.ToLower(header)}, data)
+	case "todotxt":
+		for _, item := range items {
+			fmt.Println(item)
+		}
+		return nil
+	}
+
+	if len(items) == 0 {
+		fmt.Println(empty)
+		return nil
+	}
+
+	drawTable([]string{header}, data)
+	return nil
+}
+
+// printTable writes rows of data in the selected output format. Structured formats describe each
+// row as an object keyed by the lowercased header. There is no todo.txt form of a table, so
+// todotxt output falls back to the table.
+func printTable(header []string, data [][]string, empty string) error {
+	format, err := getOutputFormat()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, len(header))
+	for i, column := range header {
+		keys[i] = strings.ReplaceAll(strings.ToLower(column), " ", "_")
+	}
+
+	// MapSlice keeps the columns in order for both JSON and YAML
+	rows := make([]yaml.MapSlice, len(data))
+	for i, values := range data {
+		row := make(yaml.MapSlice, len(keys))
+		for j, key := range keys {
+			row[j] = yaml.MapItem{Key: key, Value: values[j]}
+		}
+		rows[i] = row
+	}
+
+	switch format {
+	case "json", "ndjson":
+		objects := make([]interface{}, len(rows))
+		for i, row := range rows {
+			objects[i] = orderedObject(row)
+		}
+		if format == "ndjson" {
+			return printNDJSON(objects...)
+		}
+		return printJSON(objects)
+	case "yaml":
+		return printYAML(rows)
+	case "csv":
+		return printCSV(keys, data)
+	}
+
+	if len(data) == 0 {
+		fmt.Println(empty)
+		return nil
+	}
+
+	drawTable(header, data)
+	return nil
+}
+
+// orderedObject encodes a row as a JSON object, keeping its columns in order
+type orderedObject yaml.MapSlice
+
+// MarshalJSON writes the columns of the row in order
+func (me orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, item := range me {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, err := json.Marshal(item.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+
+	return buf.Bytes(), nil
+}
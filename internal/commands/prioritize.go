@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/dkrichards86/gotodo/internal/gotodo"
@@ -10,45 +9,40 @@ import (
 )
 
 var priCmd = &cobra.Command{
-	Use:     "prioritize [TODO ID] [PRIORITY]",
-	Short:   "Updates the priority of a todo",
+	Use:     "prioritize [TODO ID...] [PRIORITY]",
+	Short:   "Updates the priority of todos",
 	Aliases: []string{"pri"},
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 2 {
-			return fmt.Errorf("accepts 2 arg(s), received %d", len(args))
+		if len(args) < 1 {
+			return fmt.Errorf("requires at least 1 arg(s), only received %d", len(args))
 		}
 
-		if gotodo.IsPriorityString(args[1]) {
+		priorityArg := args[len(args)-1]
+		if gotodo.IsPriorityString(priorityArg) {
 			return nil
 		}
 
-		return fmt.Errorf("invalid priority value: %s", args[1])
+		return fmt.Errorf("invalid priority value: %s", priorityArg)
 	},
 	RunE: priFunc,
 }
 
 func init() {
 	rootCmd.AddCommand(priCmd)
+	addSelectionFlags(priCmd)
 }
 
 func priFunc(cmd *cobra.Command, args []string) error {
 	var err error
 	todoManager := getManager()
 
-	todoNum := args[0]
-	todoID, err := strconv.Atoi(todoNum)
+	priorityArg := args[len(args)-1]
+	todoIDs, err := selectTodos(cmd, todoManager, args[:len(args)-1], gotodo.ListAll)
 	if err != nil {
 		return err
 	}
 
-	priorityArg := args[1]
-	err = todoManager.Prioritize(todoID, priorityArg)
+	results, err := todoManager.PrioritizeAll(todoIDs, priorityArg)
 
-	if err != nil {
-		return err
-	}
-
-	fmt.Printf("Updated priority for Todo ID %d to (%s)\n", todoID, strings.ToUpper(priorityArg))
-
-	return nil
+	return printResults(results, err, "Updated priority for Todo ID %[2]d to (%[1]s)", strings.ToUpper(priorityArg))
 }
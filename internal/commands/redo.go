@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Reapplies the last undone change, or the last --count undone changes",
+	Args:  noCountArgs,
+	RunE:  redoFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(redoCmd)
+	redoCmd.Flags().IntP("count", "n", 1, "number of changes to reapply")
+}
+
+func redoFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	steps, err := getSteps(cmd)
+	if err != nil {
+		return err
+	}
+
+	entries, err := todoManager.Redo(steps)
+	for _, entry := range entries {
+		fmt.Printf("Redid %s from %s\n", entry.Operation, entry.Time.Format(historyTimeFormat))
+	}
+
+	return err
+}
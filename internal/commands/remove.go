@@ -1,41 +1,32 @@
 package commands
 
 import (
-	"fmt"
-	"strconv"
-
+	"github.com/dkrichards86/gotodo/internal/gotodo"
 	"github.com/spf13/cobra"
 )
 
 var removeCmd = &cobra.Command{
-	Use:     "remove [TODO ID]",
-	Short:   "Deletes a todo",
+	Use:     "remove [TODO ID...]",
+	Short:   "Deletes todos",
 	Aliases: []string{"rm"},
-	Args:    cobra.ExactArgs(1),
 	RunE:    removeFunc,
 }
 
 func init() {
 	rootCmd.AddCommand(removeCmd)
+	addSelectionFlags(removeCmd)
 }
 
 func removeFunc(cmd *cobra.Command, args []string) error {
 	var err error
 	todoManager := getManager()
 
-	todoNum := args[0]
-	todoID, err := strconv.Atoi(todoNum)
-	if err != nil {
-		return err
-	}
-
-	err = todoManager.Delete(todoID)
-
+	todoIDs, err := selectTodos(cmd, todoManager, args, gotodo.ListAll)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Removed Todo ID %d\n", todoID)
+	results, err := todoManager.DeleteAll(todoIDs)
 
-	return nil
+	return printResults(results, err, "Removed Todo ID %d")
 }
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+// addRenameFlags adds the flags shared by the rename commands
+func addRenameFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("dry-run", false, "show the todos that would change without saving them")
+}
+
+// runRename renames a project, context or attribute with fn, then lists the todos changed
+func runRename(cmd *cobra.Command, args []string, kind string, fn func(oldName string, newName string, dryRun bool) ([]gotodo.TodoChange, error)) error {
+	dryRunFlag, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	oldName, newName := args[0], args[1]
+	changes, err := fn(oldName, newName, dryRunFlag)
+	if err != nil {
+		return err
+	}
+
+	if !dryRunFlag {
+		fmt.Printf("Renamed %s \"%s\" to \"%s\" in %d todos\n", kind, oldName, newName, len(changes))
+		return nil
+	}
+
+	header := []string{"ID", "Before", "After"}
+	data := make([][]string, len(changes))
+	for i, change := range changes {
+		data[i] = []string{fmt.Sprintf("%d", change.TodoID), change.Before, change.After}
+	}
+
+	return printTable(header, data, "No todos would change.")
+}
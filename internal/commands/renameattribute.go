@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var renameAttributeCmd = &cobra.Command{
+	Use:   "renameattribute [OLD KEY] [NEW KEY]",
+	Short: "Rename an attribute key in every todo",
+	Args:  cobra.ExactArgs(2),
+	RunE:  renameAttributeFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(renameAttributeCmd)
+	addRenameFlags(renameAttributeCmd)
+}
+
+func renameAttributeFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	return runRename(cmd, args, "attribute", todoManager.RenameAttribute)
+}
@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var renameContextCmd = &cobra.Command{
+	Use:   "renamecontext [OLD CONTEXT] [NEW CONTEXT]",
+	Short: "Rename a context in every todo",
+	Args:  cobra.ExactArgs(2),
+	RunE:  renameContextFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(renameContextCmd)
+	addRenameFlags(renameContextCmd)
+}
+
+func renameContextFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	return runRename(cmd, args, "context", todoManager.RenameContext)
+}
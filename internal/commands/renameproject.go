@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var renameProjectCmd = &cobra.Command{
+	Use:   "renameproject [OLD PROJECT] [NEW PROJECT]",
+	Short: "Rename a project in every todo",
+	Args:  cobra.ExactArgs(2),
+	RunE:  renameProjectFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(renameProjectCmd)
+	addRenameFlags(renameProjectCmd)
+}
+
+func renameProjectFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	return runRename(cmd, args, "project", todoManager.RenameProject)
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+// reportDays is the number of days, up to today, a report covers by default
+const reportDays = 7
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Totals the time tracked on todos per project, context or day",
+	Args:  cobra.NoArgs,
+	RunE:  reportFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().String("from", "", "first day of the report, YYYY-MM-DD (default 6 days ago)")
+	reportCmd.Flags().String("to", "", "last day of the report, YYYY-MM-DD (default today)")
+	reportCmd.Flags().String("by", gotodo.ReportByProject, "group time by project, context or day")
+}
+
+// getReportDate reads a YYYY-MM-DD date flag as a local date, or returns fallback when it's unset
+func getReportDate(cmd *cobra.Command, name string, fallback time.Time) (time.Time, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil || value == "" {
+		return fallback, err
+	}
+
+	date, err := time.ParseInLocation(gotodo.TimeFormat, value, time.Local)
+	if err != nil {
+		return date, fmt.Errorf("Invalid --%s date \"%s\", use YYYY-MM-DD", name, value)
+	}
+
+	return date, nil
+}
+
+func reportFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	now := time.Now()
+	toFlag, err := getReportDate(cmd, "to", now)
+	if err != nil {
+		return err
+	}
+	fromFlag, err := getReportDate(cmd, "from", toFlag.AddDate(0, 0, 1-reportDays))
+	if err != nil {
+		return err
+	}
+	if toFlag.Before(fromFlag) {
+		return fmt.Errorf("--to %s is before --from %s", toFlag.Format(gotodo.TimeFormat), fromFlag.Format(gotodo.TimeFormat))
+	}
+
+	byFlag, err := cmd.Flags().GetString("by")
+	if err != nil {
+		return err
+	}
+
+	rows, err := todoManager.TimeReport(fromFlag, toFlag, byFlag)
+	if err != nil {
+		return err
+	}
+
+	header := []string{strings.Title(byFlag), "Time", "Hours"}
+	data := make([][]string, 0, len(rows)+1)
+	var total time.Duration
+	for _, row := range rows {
+		key := row.Key
+		switch {
+		case key == "":
+			key = "(none)"
+		case byFlag == gotodo.ReportByProject:
+			key = "+" + key
+		case byFlag == gotodo.ReportByContext:
+			key = "@" + key
+		}
+
+		data = append(data, []string{key, formatDuration(row.Time), fmt.Sprintf("%.2f", row.Time.Hours())})
+		total += row.Time
+	}
+
+	// Time on a todo in several projects or contexts counts towards each of them, so only days add
+	// up to a total. Structured output holds nothing but the groups.
+	format, err := getOutputFormat()
+	if err != nil {
+		return err
+	}
+	if format == "table" && len(data) > 1 && byFlag == gotodo.ReportByDay {
+		data = append(data, []string{"Total", formatDuration(total), fmt.Sprintf("%.2f", total.Hours())})
+	}
+
+	empty := fmt.Sprintf("No time tracked from %s to %s.", fromFlag.Format(gotodo.TimeFormat), toFlag.Format(gotodo.TimeFormat))
+	return printTable(header, data, empty)
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [FILE]",
+	Short: "Load todos from a backup",
+	Long: `Load every bucket of a backup made by the backup command, or of standard input when FILE is
+"-", into the buckets of the same name. Use --only to load a single bucket, and --into to load
+it into a bucket with another name, which is created if it doesn't exist. Todos keep their IDs.
+
+A bucket that already has todos is only overwritten with --replace. Restoring can't be undone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: restoreFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().String("only", "", "only restore this bucket of the backup")
+	restoreCmd.Flags().String("into", "", "restore the bucket into a bucket with this name")
+	restoreCmd.Flags().Bool("replace", false, "overwrite buckets that already have todos")
+}
+
+func restoreFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	onlyFlag, err := cmd.Flags().GetString("only")
+	if err != nil {
+		return err
+	}
+	intoFlag, err := cmd.Flags().GetString("into")
+	if err != nil {
+		return err
+	}
+	replaceFlag, err := cmd.Flags().GetBool("replace")
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	backup, err := gotodo.ReadBackup(r)
+	if err != nil {
+		return err
+	}
+
+	// A backup of a single bucket doesn't need --only to pick it
+	if onlyFlag == "" && intoFlag != "" {
+		if len(backup.Buckets) != 1 {
+			return errors.New("requires --only to pick the bucket restored --into, the backup has several")
+		}
+		onlyFlag = backup.Buckets[0].Name
+	}
+
+	into := make(map[string]string)
+	if onlyFlag != "" {
+		into[onlyFlag] = onlyFlag
+		if intoFlag != "" {
+			into[onlyFlag] = intoFlag
+		}
+	} else {
+		for _, bucket := range backup.Buckets {
+			into[bucket.Name] = bucket.Name
+		}
+	}
+
+	err = todoManager.RestoreBackup(backup, into, replaceFlag)
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range backup.Buckets {
+		if name, ok := into[bucket.Name]; ok {
+			fmt.Printf("Restored %d todos to %s\n", len(bucket.Todos), name)
+		}
+	}
+
+	return nil
+}
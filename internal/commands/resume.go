@@ -1,41 +1,32 @@
 package commands
 
 import (
-	"fmt"
-	"strconv"
-
+	"github.com/dkrichards86/gotodo/internal/gotodo"
 	"github.com/spf13/cobra"
 )
 
 var resumeCmd = &cobra.Command{
-	Use:     "resume [TODO ID]",
-	Short:   "Mark a todo as incomplete",
-	Aliases: []string{"undo"},
-	Args:    cobra.ExactArgs(1),
+	Use:     "resume [TODO ID...]",
+	Short:   "Mark todos as incomplete",
+	Aliases: []string{"uncomplete"},
 	RunE:    resumeFunc,
 }
 
 func init() {
 	rootCmd.AddCommand(resumeCmd)
+	addSelectionFlags(resumeCmd)
 }
 
 func resumeFunc(cmd *cobra.Command, args []string) error {
 	var err error
 	todoManager := getManager()
 
-	todoNum := args[0]
-	todoID, err := strconv.Atoi(todoNum)
-	if err != nil {
-		return err
-	}
-
-	err = todoManager.Resume(todoID)
-
+	todoIDs, err := selectTodos(cmd, todoManager, args, gotodo.ListDone)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Resumed Todo ID %d\n", todoID)
+	results, err := todoManager.ResumeAll(todoIDs)
 
-	return nil
+	return printResults(results, err, "Resumed Todo ID %d")
 }
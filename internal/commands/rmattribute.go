@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var rmAttributeCmd = &cobra.Command{
+	Use:   "rmattribute [TODO ID] [KEY]",
+	Short: "Remove an attribute from a todo",
+	Args:  cobra.ExactArgs(2),
+	RunE:  rmAttributeFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(rmAttributeCmd)
+}
+
+func rmAttributeFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	todoNum := args[0]
+	todoID, err := strconv.Atoi(todoNum)
+	if err != nil {
+		return err
+	}
+
+	key := args[1]
+	err = todoManager.RemoveAttribute(todoID, key)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed attribute \"%s\" from Todo ID %d\n", key, todoID)
+
+	return nil
+}
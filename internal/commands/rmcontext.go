@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+var rmContextCmd = &cobra.Command{
+	Use:   "rmcontext [TODO ID...] [CONTEXT]",
+	Short: "Remove a context from todos",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  rmContextFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(rmContextCmd)
+	addSelectionFlags(rmContextCmd)
+}
+
+func rmContextFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	context := args[len(args)-1]
+	todoIDs, err := selectTodos(cmd, todoManager, args[:len(args)-1], gotodo.ListAll)
+	if err != nil {
+		return err
+	}
+
+	results, err := todoManager.RemoveContextAll(todoIDs, context)
+
+	return printResults(results, err, "Removed context \"%s\" from Todo ID %d", context)
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+var rmProjectCmd = &cobra.Command{
+	Use:   "rmproject [TODO ID...] [PROJECT]",
+	Short: "Remove a project from todos",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  rmProjectFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(rmProjectCmd)
+	addSelectionFlags(rmProjectCmd)
+}
+
+func rmProjectFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	project := args[len(args)-1]
+	todoIDs, err := selectTodos(cmd, todoManager, args[:len(args)-1], gotodo.ListAll)
+	if err != nil {
+		return err
+	}
+
+	results, err := todoManager.RemoveProjectAll(todoIDs, project)
+
+	return printResults(results, err, "Removed project \"%s\" from Todo ID %d", project)
+}
@@ -3,10 +3,11 @@ package commands
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/dkrichards86/gotodo/internal/gotodo"
 	"github.com/mitchellh/go-homedir"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,6 +15,9 @@ import (
 var cfgFile string
 var todoList string
 
+// manager is built on first use by getManager and closed when the command finishes
+var manager *gotodo.TodoManager
+
 var rootCmd = &cobra.Command{
 	Use:          "gotodo",
 	Short:        "A CLI client to manage your todos",
@@ -23,14 +27,20 @@ var rootCmd = &cobra.Command{
 // Execute runs the specified command.
 func Execute() {
 	rootCmd.Execute()
+
+	if manager != nil {
+		manager.Close()
+	}
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gotodo.yaml)")
 	rootCmd.PersistentFlags().StringVar(&todoList, "bucket", "", "todo bucket to use")
+	rootCmd.PersistentFlags().StringP("output", "o", "", "output format: "+strings.Join(outputFormats, ", "))
 
 	viper.BindPFlag("bucket", rootCmd.PersistentFlags().Lookup("bucket"))
+	viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
 }
 
 func initConfig() {
@@ -47,27 +57,81 @@ func initConfig() {
 	}
 
 	viper.SetDefault("bucket", "Todos")
+	viper.SetDefault("output", "table")
+	viper.SetDefault("storage", "bolt")
+	viper.SetDefault("todo_file", "~/todo.txt")
+	viper.SetDefault("auto_archive", false)
+	viper.SetDefault("due_prioritization_rate", 0)
+	viper.SetDefault("journal_limit", 100)
 	viper.AutomaticEnv()
 	err := viper.ReadInConfig()
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+
+	switch viper.GetString("storage") {
+	case "bolt", "file":
+	default:
+		fmt.Printf("Unsupported storage \"%s\", use \"bolt\" or \"file\"\n", viper.GetString("storage"))
+		os.Exit(1)
+	}
 }
 
-func getManager() *gotodo.TodoManager {
-	bucket := viper.Get("bucket").(string)
-	return gotodo.NewTodoManager(
-		gotodo.WithBoltStorage(bucket),
-	)
+// bucketLogs returns the default journal and time log of a Bolt bucket
+func bucketLogs(bucket string) (journal string, timeLog string) {
+	return "~/.gotodo." + bucket + ".journal", "~/.gotodo." + bucket + ".time"
 }
 
-func drawTable(header []string, data [][]string) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader(header)
-	table.SetAutoWrapText(false)
-	table.SetBorder(false)
-	table.SetAutoFormatHeaders(true)
-	table.AppendBulk(data)
-	table.Render()
+func getManager() *gotodo.TodoManager {
+	if manager != nil {
+		return manager
+	}
+
+	opts := []gotodo.TodoManagerOptions{
+		gotodo.WithAutoArchive(viper.GetBool("auto_archive")),
+		gotodo.WithDuePrioritization(viper.GetInt("due_prioritization_rate")),
+	}
+
+	// The journal and time log live next to the todos they record, unless configured otherwise
+	journal := viper.GetString("journal_file")
+	timeLog := viper.GetString("time_file")
+
+	// Sessions in the time log are recorded under the bucket name, or the path of todo_file
+	var list string
+	if viper.GetString("storage") == "file" {
+		todoFile := viper.GetString("todo_file")
+		list = todoFile
+		opts = append(opts, gotodo.WithFileStorage(todoFile))
+		if archive := viper.GetString("done_file"); archive != "" {
+			opts = append(opts, gotodo.WithFileArchive(archive))
+		}
+		if journal == "" {
+			journal = filepath.Join(filepath.Dir(todoFile), "."+filepath.Base(todoFile)+".journal")
+		}
+		if timeLog == "" {
+			timeLog = filepath.Join(filepath.Dir(todoFile), "."+filepath.Base(todoFile)+".time")
+		}
+	} else {
+		bucket := viper.GetString("bucket")
+		list = bucket
+		opts = append(opts, gotodo.WithBoltStorage(bucket))
+		if archive := viper.GetString("archive_bucket"); archive != "" {
+			opts = append(opts, gotodo.WithBoltArchive(archive))
+		}
+		bucketJournal, bucketTimeLog := bucketLogs(bucket)
+		if journal == "" {
+			journal = bucketJournal
+		}
+		if timeLog == "" {
+			timeLog = bucketTimeLog
+		}
+	}
+
+	opts = append(opts, gotodo.WithJournal(journal, viper.GetInt("journal_limit")))
+	opts = append(opts, gotodo.WithTimeLog(timeLog, list))
+
+	manager = gotodo.NewTodoManager(opts...)
+
+	return manager
 }
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+// ANSI escapes used to highlight search matches
+const (
+	highlightStart = "\033[1;4m"
+	highlightEnd   = "\033[0m"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [WORDS]",
+	Short: "Finds pending, completed and archived todos by their description",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  searchFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().Int("limit", 0, "maximum number of results to show")
+}
+
+// useHighlight checks whether stdout is a terminal that should show highlighted matches
+func useHighlight() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func searchFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	limitFlag, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		return err
+	}
+
+	results, err := todoManager.Search(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	if limitFlag > 0 && len(results) > limitFlag {
+		results = results[:limitFlag]
+	}
+
+	before, after := "", ""
+	if useHighlight() {
+		before, after = highlightStart, highlightEnd
+	}
+
+	items := make(gotodo.TodoList, len(results))
+	header := []string{"ID", "List", "Todo"}
+	data := make([][]string, len(results))
+	for i, result := range results {
+		items[i] = result.Todo
+
+		list := "pending"
+		if result.Archived {
+			list = "archived"
+		} else if result.Todo.Complete {
+			list = "done"
+		}
+
+		data[i] = []string{
+			fmt.Sprintf("%d", result.Todo.TodoID),
+			list,
+			result.Highlight(before, after),
+		}
+	}
+
+	return printTodos(items, header, data)
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/spf13/cobra"
+)
+
+// addSelectionFlags adds the flags bulk commands use to select todos besides their IDs
+func addSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().String("query", "", "select todos matching a query")
+	cmd.Flags().String("project", "", "select todos in a project")
+}
+
+// maxIDRange is the most IDs a range like 9-12 can hold, so a mistyped range doesn't expand into
+// millions of IDs
+const maxIDRange = 1000
+
+// parseIDs parses todo IDs, which may be given as ranges like 9-12
+func parseIDs(args []string) ([]int, error) {
+	todoIDs := make([]int, 0, len(args))
+
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "-", 2)
+		first, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid todo ID: %s", arg)
+		}
+
+		last := first
+		if len(parts) == 2 {
+			last, err = strconv.Atoi(parts[1])
+			if err != nil || last < first {
+				return nil, fmt.Errorf("invalid todo ID range: %s", arg)
+			}
+			if last-first >= maxIDRange {
+				return nil, fmt.Errorf("todo ID range %s is too large, a range can hold at most %d IDs", arg, maxIDRange)
+			}
+		}
+
+		for todoID := first; todoID <= last; todoID++ {
+			todoIDs = append(todoIDs, todoID)
+		}
+	}
+
+	return todoIDs, nil
+}
+
+// selectTodos returns the todo IDs given as args, followed by the IDs of todos with the given
+// status matched by the --query and --project flags
+func selectTodos(cmd *cobra.Command, todoManager *gotodo.TodoManager, args []string, status int) ([]int, error) {
+	todoIDs, err := parseIDs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	queryFlag, err := cmd.Flags().GetString("query")
+	if err != nil {
+		return nil, err
+	}
+
+	projectFlag, err := cmd.Flags().GetString("project")
+	if err != nil {
+		return nil, err
+	}
+
+	if queryFlag == "" && projectFlag == "" {
+		if len(todoIDs) == 0 {
+			return nil, errors.New("requires a todo ID, --query or --project")
+		}
+		return todoIDs, nil
+	}
+
+	items, err := todoManager.List(gotodo.TodoListFilter{
+		Status:      status,
+		Project:     projectFlag,
+		Query:       queryFlag,
+		ShowFuture:  true,
+		ShowBlocked: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 && len(todoIDs) == 0 {
+		return nil, errors.New("No todos matched")
+	}
+
+	for _, todo := range items {
+		todoIDs = append(todoIDs, todo.TodoID)
+	}
+
+	return todoIDs, nil
+}
+
+// printResults reports the outcome of a bulk command. When it succeeded, format is printed for
+// every todo with args followed by the todo's ID. Otherwise every todo is listed with its error,
+// if it had one.
+func printResults(results []gotodo.BulkResult, err error, format string, args ...interface{}) error {
+	if err == nil {
+		for _, result := range results {
+			fmt.Printf(format+"\n", append(args, result.TodoID)...)
+		}
+		return nil
+	}
+
+	// a single todo fails the same way it always has
+	if len(results) == 1 && results[0].Err != nil {
+		return results[0].Err
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Printf("Todo ID %d: %s\n", result.TodoID, result.Err)
+		} else {
+			fmt.Printf("Todo ID %d: not changed\n", result.TodoID)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dkrichards86/gotodo/internal/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serves your todos over a JSON REST API",
+	Args:  cobra.NoArgs,
+	RunE:  serveFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("addr", "localhost:8080", "address to listen on")
+	serveCmd.Flags().String("token", "", "bearer token required by every request")
+
+	viper.BindPFlag("serve_addr", serveCmd.Flags().Lookup("addr"))
+	viper.BindPFlag("serve_token", serveCmd.Flags().Lookup("token"))
+}
+
+func serveFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	opts := make([]server.ServerOptions, 0)
+	if token := viper.GetString("serve_token"); token != "" {
+		opts = append(opts, server.WithToken(token))
+	}
+
+	httpServer := &http.Server{
+		Addr:    viper.GetString("serve_addr"),
+		Handler: server.New(todoManager, opts...),
+	}
+
+	// Finish the requests in progress before exiting on an interrupt
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		httpServer.Shutdown(context.Background())
+	}()
+
+	fmt.Printf("Serving todos on http://%s\n", httpServer.Addr)
+	err := httpServer.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+
+	return err
+}
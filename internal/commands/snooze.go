@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze [TODO ID] [AMOUNT]",
+	Short: "Hides a todo by moving its threshold date forward, e.g. by 3d, 2w or 1m",
+	Args:  cobra.ExactArgs(2),
+	RunE:  snoozeFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(snoozeCmd)
+}
+
+func snoozeFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	todoNum := args[0]
+	todoID, err := strconv.Atoi(todoNum)
+	if err != nil {
+		return err
+	}
+
+	amount := args[1]
+	err = todoManager.Snooze(todoID, amount)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Snoozed Todo ID %d by %s\n", todoID, amount)
+
+	return nil
+}
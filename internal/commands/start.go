@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start [TODO ID]",
+	Short: "Starts tracking time spent on a todo",
+	Args:  cobra.ExactArgs(1),
+	RunE:  startFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(startCmd)
+}
+
+func startFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	todoNum := args[0]
+	todoID, err := strconv.Atoi(todoNum)
+	if err != nil {
+		return err
+	}
+
+	session, err := todoManager.Start(todoID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Started Todo ID %d at %s\n", todoID, session.Start.Format(sessionTimeFormat))
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// sessionTimeFormat is how the start and end of work sessions are displayed
+const sessionTimeFormat = "15:04"
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [TODO ID]",
+	Short: "Stops tracking time spent on a todo",
+	Args:  cobra.ExactArgs(1),
+	RunE:  stopFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+}
+
+func stopFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	todoNum := args[0]
+	todoID, err := strconv.Atoi(todoNum)
+	if err != nil {
+		return err
+	}
+
+	session, err := todoManager.Stop(todoID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Stopped Todo ID %d at %s after %s\n", todoID, session.End.Format(sessionTimeFormat), formatDuration(session.Duration(time.Now())))
+
+	return nil
+}
+
+// formatDuration displays a duration in hours and minutes, like 2h05m
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"github.com/dkrichards86/gotodo/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Manage your todos in a full-screen terminal interface",
+	Args:  cobra.NoArgs,
+	RunE:  tuiFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+func tuiFunc(cmd *cobra.Command, args []string) error {
+	todoManager := getManager()
+
+	return tui.Run(todoManager)
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Reverts the last change, or the last --count changes",
+	Long: `Reverts the last change, or the last --count changes.
+
+undo used to be an alias of resume, so "undo 12" would mark todo 12 as incomplete. undo takes no
+arguments, so that spelling is refused rather than reverting the last 12 changes. Use
+"resume 12" to mark a todo as incomplete.`,
+	Args: noCountArgs,
+	RunE: undoFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	undoCmd.Flags().IntP("count", "n", 1, "number of changes to revert")
+}
+
+// noCountArgs refuses arguments to undo and redo, which take how many changes to revert or
+// reapply as --count
+func noCountArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s takes no arguments, use --count to %s several changes or resume to mark a todo as incomplete", cmd.Name(), cmd.Name())
+}
+
+// getSteps reads the --count flag of undo and redo
+func getSteps(cmd *cobra.Command) (int, error) {
+	steps, err := cmd.Flags().GetInt("count")
+	if err != nil {
+		return 0, err
+	}
+	if steps < 1 {
+		return 0, fmt.Errorf("invalid count: %d", steps)
+	}
+
+	return steps, nil
+}
+
+func undoFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	todoManager := getManager()
+
+	steps, err := getSteps(cmd)
+	if err != nil {
+		return err
+	}
+
+	entries, err := todoManager.Undo(steps)
+	for _, entry := range entries {
+		fmt.Printf("Undid %s from %s\n", entry.Operation, entry.Time.Format(historyTimeFormat))
+	}
+
+	return err
+}
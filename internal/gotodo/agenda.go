@@ -0,0 +1,112 @@
+package gotodo
+
+import (
+	"sort"
+	"time"
+)
+
+// The groups of an agenda, in the order Agenda returns them
+const (
+	AgendaOverdue  = "Overdue"
+	AgendaToday    = "Today"
+	AgendaTomorrow = "Tomorrow"
+	AgendaThisWeek = "This week"
+	AgendaLater    = "Later"
+	AgendaNoDate   = "No date"
+)
+
+// AgendaGroup is the pending todos due in one part of an agenda
+type AgendaGroup struct {
+	Name  string
+	Todos TodoList
+}
+
+// Agenda groups the pending todos of items by when they are due, counting from the day of now:
+// overdue, today, tomorrow, the rest of this week, later, and todos with no due date. Weeks end on
+// Sunday, so on a Saturday or Sunday there is nothing left of this week after tomorrow. Every group
+// is returned, even when empty. Todos in a group are ordered by due date, and otherwise keep the
+// order of items.
+func Agenda(items TodoList, now time.Time) []AgendaGroup {
+	today := dateOf(now)
+	tomorrow := today.AddDate(0, 0, 1)
+	endOfWeek := today.AddDate(0, 0, (7-int(today.Weekday()))%7)
+
+	names := []string{AgendaOverdue, AgendaToday, AgendaTomorrow, AgendaThisWeek, AgendaLater, AgendaNoDate}
+	groups := make([]AgendaGroup, len(names))
+	index := make(map[string]int)
+	for i, name := range names {
+		groups[i] = AgendaGroup{Name: name, Todos: make(TodoList, 0)}
+		index[name] = i
+	}
+
+	for _, todo := range items {
+		if todo.Complete {
+			continue
+		}
+
+		due := todo.DueDate.Time
+		name := AgendaLater
+		switch {
+		case !todo.DueDate.Valid:
+			name = AgendaNoDate
+		case due.Before(today):
+			name = AgendaOverdue
+		case due.Equal(today):
+			name = AgendaToday
+		case due.Equal(tomorrow):
+			name = AgendaTomorrow
+		case !due.After(endOfWeek):
+			name = AgendaThisWeek
+		}
+
+		group := &groups[index[name]]
+		group.Todos = append(group.Todos, todo)
+	}
+
+	for _, group := range groups {
+		todos := group.Todos
+		sort.SliceStable(todos, func(i, j int) bool {
+			return todos[i].DueDate.Valid && todos[i].DueDate.Time.Before(todos[j].DueDate.Time)
+		})
+	}
+
+	return groups
+}
+
+// CalendarDay is the todos due on a day, and those whose threshold date makes them start on it
+type CalendarDay struct {
+	Date   time.Time
+	Due    TodoList
+	Starts TodoList
+}
+
+// Calendar returns every day of the month month is in, with the todos of items due or starting on
+// it in the order of items
+func Calendar(items TodoList, month time.Time) []CalendarDay {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+
+	days := make([]CalendarDay, last.Day())
+	for i := range days {
+		days[i] = CalendarDay{Date: first.AddDate(0, 0, i), Due: make(TodoList, 0), Starts: make(TodoList, 0)}
+	}
+
+	// inMonth returns the index of the day of date, or -1 when it's another month
+	inMonth := func(date NullTime) int {
+		if !date.Valid || date.Time.Before(first) || date.Time.After(last) {
+			return -1
+		}
+		return date.Time.Day() - 1
+	}
+
+	for _, todo := range items {
+		if day := inMonth(todo.DueDate); day >= 0 {
+			days[day].Due = append(days[day].Due, todo)
+		}
+		if day := inMonth(todo.ThresholdDate); day >= 0 {
+			days[day].Starts = append(days[day].Starts, todo)
+		}
+	}
+
+	return days
+}
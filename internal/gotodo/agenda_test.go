@@ -0,0 +1,95 @@
+package gotodo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// agendaIDs returns the IDs of the todos in each group of an agenda, by group name
+func agendaIDs(groups []AgendaGroup) map[string][]int {
+	ids := make(map[string][]int)
+	for _, group := range groups {
+		ids[group.Name] = make([]int, 0)
+		for _, todo := range group.Todos {
+			ids[group.Name] = append(ids[group.Name], todo.TodoID)
+		}
+	}
+
+	return ids
+}
+
+// getTestTodoList parses todo strings into a list, numbered from 1
+func getTestTodoList(todoStrs ...string) TodoList {
+	items := make(TodoList, len(todoStrs))
+	for i, todoStr := range todoStrs {
+		items[i] = FromString(todoStr)
+		items[i].TodoID = i + 1
+	}
+
+	return items
+}
+
+func TestAgenda(t *testing.T) {
+	// 2020-04-29 is a Wednesday
+	now := time.Date(2020, time.April, 29, 15, 30, 0, 0, time.Local)
+	items := getTestTodoList(
+		"Pay rent due:2020-04-01",
+		"Call mom due:2020-04-29",
+		"Write report due:2020-05-03",
+		"Send invoice due:2020-04-30",
+		"Plan sprint due:2020-05-04",
+		"Water plants",
+		"x 2020-04-28 Old task due:2020-04-20",
+		"Fix fence due:2020-05-01",
+		"Renew passport due:2020-04-28",
+	)
+
+	groups := Agenda(items, now)
+	assert.Equal(t, []string{AgendaOverdue, AgendaToday, AgendaTomorrow, AgendaThisWeek, AgendaLater, AgendaNoDate}, []string{
+		groups[0].Name, groups[1].Name, groups[2].Name, groups[3].Name, groups[4].Name, groups[5].Name,
+	})
+	assert.Equal(t, map[string][]int{
+		AgendaOverdue:  {1, 9},
+		AgendaToday:    {2},
+		AgendaTomorrow: {4},
+		AgendaThisWeek: {8, 3},
+		AgendaLater:    {5},
+		AgendaNoDate:   {6},
+	}, agendaIDs(groups))
+
+	// on a Saturday, Sunday is tomorrow and Monday is next week
+	saturday := time.Date(2020, time.May, 2, 9, 0, 0, 0, time.Local)
+	ids := agendaIDs(Agenda(items, saturday))
+	assert.Equal(t, []int{3}, ids[AgendaTomorrow])
+	assert.Equal(t, []int{}, ids[AgendaThisWeek])
+	assert.Equal(t, []int{5}, ids[AgendaLater])
+}
+
+func TestCalendar(t *testing.T) {
+	items := getTestTodoList(
+		"Pay rent due:2020-05-01 t:2020-04-25",
+		"Call mom due:2020-05-01",
+		"Write report t:2020-05-31 due:2020-06-02",
+		"Water plants",
+	)
+
+	days := Calendar(items, time.Date(2020, time.May, 17, 0, 0, 0, 0, time.Local))
+	assert.Equal(t, 31, len(days))
+	assert.Equal(t, "2020-05-01", days[0].Date.Format(TimeFormat))
+	assert.Equal(t, "2020-05-31", days[30].Date.Format(TimeFormat))
+
+	assert.Equal(t, TodoList{items[0], items[1]}, days[0].Due)
+	assert.Empty(t, days[0].Starts)
+	assert.Equal(t, TodoList{items[2]}, days[30].Starts)
+	assert.Empty(t, days[30].Due)
+
+	for _, day := range days[1:30] {
+		assert.Empty(t, day.Due, day.Date.Format(TimeFormat))
+		assert.Empty(t, day.Starts, day.Date.Format(TimeFormat))
+	}
+
+	days = Calendar(items, time.Date(2020, time.February, 1, 0, 0, 0, 0, time.Local))
+	assert.Equal(t, 29, len(days))
+}
@@ -0,0 +1,294 @@
+package gotodo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupHeader starts every backup, followed by the time it was made
+const backupHeader = "# gotodo backup "
+
+// backupBucketPrefix starts the line naming the bucket the todos after it belong to
+const backupBucketPrefix = "# bucket: "
+
+// Backup is a copy of every bucket of todos. It is written as text, one "[ID] todo" line per
+// Todo under a "# bucket: NAME" line per bucket, so it can be read without gotodo.
+type Backup struct {
+	Created time.Time
+	Buckets []BackupBucket
+}
+
+// BackupBucket is a bucket of todos in a Backup
+type BackupBucket struct {
+	Name  string
+	Todos TodoList
+}
+
+// Bucket returns the bucket called name, or nil if the backup has none
+func (me *Backup) Bucket(name string) *BackupBucket {
+	for i := range me.Buckets {
+		if me.Buckets[i].Name == name {
+			return &me.Buckets[i]
+		}
+	}
+
+	return nil
+}
+
+// Write writes the backup as text
+func (me *Backup) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "%s%s\n", backupHeader, me.Created.Format(time.RFC3339))
+	fmt.Fprintln(bw, "# Restore with: gotodo restore FILE")
+	for _, bucket := range me.Buckets {
+		fmt.Fprintf(bw, "\n%s%s\n", backupBucketPrefix, bucket.Name)
+		for _, todo := range bucket.Todos {
+			fmt.Fprintf(bw, "[%d] %s\n", todo.TodoID, todo.String())
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ReadBackup reads a backup written by Backup.Write
+func ReadBackup(r io.Reader) (*Backup, error) {
+	backup := &Backup{Buckets: make([]BackupBucket, 0)}
+	var bucket *BackupBucket
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), importMaxLine)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		switch {
+		case lineNum == 1:
+			if !strings.HasPrefix(line, backupHeader) {
+				return nil, errors.New("Not a gotodo backup, it doesn't start with \"" + strings.TrimSpace(backupHeader) + "\"")
+			}
+			created, err := time.Parse(time.RFC3339, strings.TrimPrefix(line, backupHeader))
+			if err != nil {
+				return nil, fmt.Errorf("Line 1: Invalid backup time: %s", err)
+			}
+			backup.Created = created
+		case strings.HasPrefix(line, backupBucketPrefix):
+			name := strings.TrimSpace(strings.TrimPrefix(line, backupBucketPrefix))
+			if backup.Bucket(name) != nil {
+				return nil, fmt.Errorf("Line %d: Bucket \"%s\" is already in the backup", lineNum, name)
+			}
+			backup.Buckets = append(backup.Buckets, BackupBucket{Name: name, Todos: make(TodoList, 0)})
+			bucket = &backup.Buckets[len(backup.Buckets)-1]
+		case strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#"):
+			continue
+		default:
+			match := editLinePattern.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("Line %d: Expected a todo like \"[3] Call mom\"", lineNum)
+			} else if bucket == nil {
+				return nil, fmt.Errorf("Line %d: Todo isn't in a bucket", lineNum)
+			}
+
+			todo := FromString(match[2])
+			todo.TodoID, _ = strconv.Atoi(match[1])
+			bucket.Todos = append(bucket.Todos, todo)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// Export writes the Todos selected by listFilter to w as a todo.txt file, in ID order, and returns
+// how many were written
+func (tm *TodoManager) Export(w io.Writer, listFilter TodoListFilter) (int, error) {
+	items, err := tm.List(listFilter)
+	if err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, todo := range items {
+		fmt.Fprintln(bw, todo.String())
+	}
+
+	return len(items), bw.Flush()
+}
+
+// storageName names the bucket of a Storage: the bucket of a BoltStorage, or the file name of a
+// FileStorage. Other storage is named after its role.
+func storageName(storage Storage, role string) string {
+	switch s := storage.(type) {
+	case *BoltStorage:
+		return string(s.Bucket)
+	case *FileStorage:
+		return filepath.Base(s.Path)
+	}
+
+	return role
+}
+
+// BucketNames returns the name of every bucket: every bucket in the database for Bolt storage,
+// otherwise the storage and the archive
+func (tm *TodoManager) BucketNames() ([]string, error) {
+	if boltStorage, ok := tm.Storage.(*BoltStorage); ok {
+		return boltStorage.Buckets()
+	}
+
+	names := []string{storageName(tm.Storage, "todos")}
+	if tm.ArchiveStorage != nil {
+		names = append(names, storageName(tm.ArchiveStorage, "archive"))
+	}
+
+	return names, nil
+}
+
+// bucketStorage returns the Storage of the bucket called name. With Bolt storage, it is a bucket
+// in the same database, created if it doesn't exist. With file storage, it is a file in the same
+// directory as the todo.txt file. The Storage must be closed with closeBucket.
+func (tm *TodoManager) bucketStorage(name string) (Storage, error) {
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, "/\\") {
+		return nil, fmt.Errorf("Invalid bucket name \"%s\"", name)
+	}
+
+	// the archive may be configured somewhere else, so use the storage and archive themselves
+	if name == storageName(tm.Storage, "todos") {
+		return tm.Storage, nil
+	} else if tm.ArchiveStorage != nil && name == storageName(tm.ArchiveStorage, "archive") {
+		return tm.ArchiveStorage, nil
+	}
+
+	switch s := tm.Storage.(type) {
+	case *BoltStorage:
+		return &BoltStorage{Bucket: []byte(name), Path: s.Path}, nil
+	case *FileStorage:
+		return &FileStorage{Path: filepath.Join(filepath.Dir(s.Path), name)}, nil
+	}
+
+	return nil, fmt.Errorf("Bucket \"%s\" does not exist", name)
+}
+
+// closeBucket releases a Storage returned by bucketStorage
+func (tm *TodoManager) closeBucket(storage Storage) error {
+	if storage == tm.Storage || storage == tm.ArchiveStorage {
+		return nil
+	}
+	if closer, ok := storage.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// Backup copies every bucket named by BucketNames
+func (tm *TodoManager) Backup() (*Backup, error) {
+	names, err := tm.BucketNames()
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &Backup{Created: time.Now(), Buckets: make([]BackupBucket, 0, len(names))}
+	for _, name := range names {
+		storage, err := tm.bucketStorage(name)
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := storage.List()
+		if closeErr := tm.closeBucket(storage); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		backup.Buckets = append(backup.Buckets, BackupBucket{Name: name, Todos: items})
+	}
+
+	return backup, nil
+}
+
+// RestoreBackup loads buckets of a backup into the buckets named by into, keeping their todo IDs.
+// into maps the name of a bucket in the backup to the bucket it is loaded into. A bucket that
+// already has todos is only replaced when replace is set. Every bucket is checked before any are
+// changed, and each is restored in a single batch. Restoring isn't recorded in the journal.
+func (tm *TodoManager) RestoreBackup(backup *Backup, into map[string]string, replace bool) error {
+	storages := make(map[string]Storage)
+	defer func() {
+		for _, storage := range storages {
+			tm.closeBucket(storage)
+		}
+	}()
+
+	// restore in the order of the backup, so errors are reported the same way every time
+	names := make([]string, 0, len(into))
+	for _, bucket := range backup.Buckets {
+		if _, ok := into[bucket.Name]; ok {
+			names = append(names, bucket.Name)
+		}
+	}
+	for from := range into {
+		if backup.Bucket(from) == nil {
+			return fmt.Errorf("Bucket \"%s\" isn't in the backup", from)
+		}
+	}
+
+	for _, from := range names {
+		to := into[from]
+		storage, err := tm.bucketStorage(to)
+		if err != nil {
+			return err
+		}
+		storages[from] = storage
+
+		items, err := storage.List()
+		if err != nil {
+			return err
+		} else if len(items) > 0 && !replace {
+			return fmt.Errorf("Bucket \"%s\" already has %d todos, restore with --replace to overwrite them", to, len(items))
+		}
+	}
+
+	for _, from := range names {
+		storage := storages[from]
+		todos := backup.Bucket(from).Todos
+
+		err := batchOne(storage, func(tx Storage) error {
+			restorer, ok := tx.(Restorer)
+			if !ok {
+				return errNoRestore
+			}
+
+			items, err := tx.List()
+			if err != nil {
+				return err
+			}
+			for _, todo := range items {
+				if err := tx.Delete(todo.TodoID); err != nil {
+					return err
+				}
+			}
+
+			for _, todo := range todos {
+				if err := restorer.Restore(todo); err != nil {
+					return fmt.Errorf("Can't restore Todo ID %d to \"%s\": %s", todo.TodoID, into[from], err)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
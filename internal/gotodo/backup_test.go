@@ -0,0 +1,125 @@
+package gotodo
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	todoManager := NewTodoManager(WithFileStorage(getTestFileStorage(t, strings.Join([]string{
+		"(B) 2020-04-28 Work on   unit tests +gotodo",
+		"",
+		"x 2020-04-29 Add parser test +gotodo",
+		"Call mom @phone t:2999-01-01",
+	}, "\n")).Path))
+
+	var out bytes.Buffer
+	count, err := todoManager.Export(&out, TodoListFilter{Status: ListAll, ShowFuture: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, "(B) 2020-04-28 Work on   unit tests +gotodo\nx 2020-04-29 Add parser test +gotodo\nCall mom @phone t:2999-01-01\n", out.String())
+
+	out.Reset()
+	count, err = todoManager.Export(&out, TodoListFilter{Status: ListPending, Query: "+gotodo"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "(B) 2020-04-28 Work on   unit tests +gotodo\n", out.String())
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	storage := getTestBoltStorage(t, "Todos")
+	todoManager := NewTodoManager(WithBoltStorage("Todos"))
+	todoManager.Storage = storage
+	todoManager.ArchiveStorage = &BoltStorage{Bucket: []byte("TodosArchive"), Path: storage.Path}
+	defer todoManager.Close()
+
+	for _, todoStr := range []string{"(A) Call mom @phone", "Plan trip +travel", "Pack bags parent:2"} {
+		_, err := todoManager.Add(todoStr)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, todoManager.Delete(1))
+	assert.NoError(t, todoManager.ArchiveStorage.Create(FromString("x 2020-04-29 Add parser test")))
+
+	backup, err := todoManager.Backup()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(backup.Buckets))
+
+	var out bytes.Buffer
+	assert.NoError(t, backup.Write(&out))
+	text := out.String()
+	assert.True(t, strings.HasPrefix(text, "# gotodo backup "))
+	assert.Contains(t, text, "# bucket: Todos\n[2] Plan trip +travel\n[3] Pack bags parent:2\n")
+	assert.Contains(t, text, "# bucket: TodosArchive\n[1] x 2020-04-29 Add parser test\n")
+
+	read, err := ReadBackup(strings.NewReader(text))
+	assert.NoError(t, err)
+	assert.Equal(t, backup.Created.Truncate(time.Second).Unix(), read.Created.Unix())
+	assert.Equal(t, []string{"Todos", "TodosArchive"}, []string{read.Buckets[0].Name, read.Buckets[1].Name})
+
+	// a new bucket gets the todos under their original IDs
+	assert.NoError(t, todoManager.RestoreBackup(read, map[string]string{"Todos": "Restored"}, false))
+	restored := &BoltStorage{Bucket: []byte("Restored"), Path: storage.Path}
+	defer restored.Close()
+	items, err := restored.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, 3, items[1].TodoID)
+	assert.Equal(t, 2, items[1].ParentID())
+
+	// an existing bucket is only overwritten with replace
+	_, err = todoManager.Add("Added after the backup")
+	assert.NoError(t, err)
+	err = todoManager.RestoreBackup(read, map[string]string{"Todos": "Todos"}, false)
+	assert.EqualError(t, err, "Bucket \"Todos\" already has 3 todos, restore with --replace to overwrite them")
+	assert.NoError(t, todoManager.RestoreBackup(read, map[string]string{"Todos": "Todos", "TodosArchive": "TodosArchive"}, true))
+
+	items, err = storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+
+	// the sequence isn't reset, so new todos don't take IDs used before the restore
+	todoID, err := todoManager.Add("Added after the restore")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, todoID)
+
+	names, err := todoManager.BucketNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Restored", "Todos", "TodosArchive"}, names)
+}
+
+func TestBackupFileStorage(t *testing.T) {
+	storage := getTestFileStorage(t, "Call mom\n\nPlan trip\n")
+	todoManager := NewTodoManager(WithFileStorage(storage.Path))
+
+	backup, err := todoManager.Backup()
+	assert.NoError(t, err)
+	assert.Equal(t, "todo.txt", backup.Buckets[0].Name)
+	assert.Equal(t, "done.txt", backup.Buckets[1].Name)
+	assert.Equal(t, 3, backup.Buckets[0].Todos[1].TodoID)
+
+	// file buckets are files next to todo.txt, keeping IDs as line numbers
+	assert.NoError(t, todoManager.RestoreBackup(backup, map[string]string{"todo.txt": "copy.txt"}, false))
+	copied := &FileStorage{Path: filepath.Join(filepath.Dir(storage.Path), "copy.txt")}
+	assert.Equal(t, "Call mom\n\nPlan trip\n", readTestFile(t, copied))
+
+	err = todoManager.RestoreBackup(backup, map[string]string{"todo.txt": "../todo.txt"}, false)
+	assert.EqualError(t, err, "Invalid bucket name \"../todo.txt\"")
+	err = todoManager.RestoreBackup(backup, map[string]string{"someday.txt": "todo.txt"}, false)
+	assert.EqualError(t, err, "Bucket \"someday.txt\" isn't in the backup")
+}
+
+func TestReadBackupErrors(t *testing.T) {
+	_, err := ReadBackup(strings.NewReader("(A) Call mom\n"))
+	assert.EqualError(t, err, "Not a gotodo backup, it doesn't start with \"# gotodo backup\"")
+
+	_, err = ReadBackup(strings.NewReader("# gotodo backup 2020-04-28T10:00:00Z\n[1] Call mom\n"))
+	assert.EqualError(t, err, "Line 2: Todo isn't in a bucket")
+
+	_, err = ReadBackup(strings.NewReader("# gotodo backup 2020-04-28T10:00:00Z\n# bucket: Todos\nCall mom\n"))
+	assert.EqualError(t, err, "Line 3: Expected a todo like \"[3] Call mom\"")
+}
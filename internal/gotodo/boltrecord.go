@@ -0,0 +1,193 @@
+package gotodo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// boltSchemaVersion is the version of the records BoltStorage writes. Version 1 stored each todo
+// as its todo.txt string under its ID in decimal, and had no schema key.
+const boltSchemaVersion = 2
+
+// boltSchemaKey holds the schema version of a bucket. Todo keys are always 8 bytes, so it can't
+// collide with one.
+var boltSchemaKey = []byte("schema")
+
+// boltRecord is a Todo as BoltStorage saves it. Every field is stored explicitly so reading a
+// Todo doesn't parse it again, and Text keeps the todo.txt line exactly as written.
+type boltRecord struct {
+	TodoID         int               `json:"id"`
+	Complete       bool              `json:"complete"`
+	Priority       int               `json:"priority,omitempty"`
+	CompletionDate string            `json:"completion_date,omitempty"`
+	CreationDate   string            `json:"creation_date,omitempty"`
+	DueDate        string            `json:"due_date,omitempty"`
+	ThresholdDate  string            `json:"threshold_date,omitempty"`
+	Description    string            `json:"description"`
+	Projects       []string          `json:"projects,omitempty"`
+	Contexts       []string          `json:"contexts,omitempty"`
+	Attributes     map[string]string `json:"attributes,omitempty"`
+	Text           string            `json:"text"`
+	Modified       time.Time         `json:"modified"`
+}
+
+// boltKey returns the key of a Todo. IDs are big-endian so the cursor walks todos in ID order.
+func boltKey(todoID int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(todoID))
+
+	return key
+}
+
+// boltTodoID returns the Todo ID of a key, or 0 for keys that don't hold a Todo
+func boltTodoID(key []byte) int {
+	if len(key) != 8 {
+		return 0
+	}
+
+	return int(binary.BigEndian.Uint64(key))
+}
+
+// encodeBoltRecord converts a Todo into the value BoltStorage saves, modified at modified
+func encodeBoltRecord(todoID int, todo *Todo, modified time.Time) ([]byte, error) {
+	return json.Marshal(boltRecord{
+		TodoID:         todoID,
+		Complete:       todo.Complete,
+		Priority:       todo.Priority,
+		CompletionDate: todo.CompletionDate.Display(),
+		CreationDate:   todo.CreationDate.Display(),
+		DueDate:        todo.DueDate.Display(),
+		ThresholdDate:  todo.ThresholdDate.Display(),
+		Description:    todo.Description,
+		Projects:       todo.Projects.Sorted(),
+		Contexts:       todo.Contexts.Sorted(),
+		Attributes:     todo.Attributes,
+		Text:           todo.String(),
+		Modified:       modified,
+	})
+}
+
+// decodeBoltRecord converts a value saved by BoltStorage back into a Todo
+func decodeBoltRecord(value []byte) (*Todo, error) {
+	var record boltRecord
+	if err := json.Unmarshal(value, &record); err != nil {
+		return nil, err
+	}
+
+	todo := &Todo{
+		TodoID:         record.TodoID,
+		Complete:       record.Complete,
+		Priority:       record.Priority,
+		CompletionDate: NewNullTime(record.CompletionDate),
+		CreationDate:   NewNullTime(record.CreationDate),
+		DueDate:        NewNullTime(record.DueDate),
+		ThresholdDate:  NewNullTime(record.ThresholdDate),
+		Description:    record.Description,
+		Projects:       make(Tags),
+		Contexts:       make(Tags),
+		Attributes:     make(Attributes),
+		raw:            record.Text,
+	}
+	for _, project := range record.Projects {
+		todo.Projects[project] = void{}
+	}
+	for _, context := range record.Contexts {
+		todo.Contexts[context] = void{}
+	}
+	for key, value := range record.Attributes {
+		todo.Attributes[key] = value
+	}
+	todo.rawCanonical = todo.canonical()
+
+	return todo, nil
+}
+
+// boltVersion returns the schema version of a bucket. A bucket without a schema key holding
+// todos is version 1, while an empty one is new.
+func boltVersion(b *bolt.Bucket) (int, error) {
+	value := b.Get(boltSchemaKey)
+	if value == nil {
+		if k, _ := b.Cursor().First(); k == nil {
+			return boltSchemaVersion, nil
+		}
+		return 1, nil
+	}
+
+	version, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0, fmt.Errorf("Invalid schema version \"%s\"", value)
+	}
+
+	return version, nil
+}
+
+// migrateBolt upgrades the records in a bucket to the current schema version. It runs inside the
+// transaction that opens the bucket, so an upgrade is never left half done.
+func migrateBolt(b *bolt.Bucket) error {
+	version, err := boltVersion(b)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version > boltSchemaVersion:
+		return fmt.Errorf("Bucket uses schema version %d, which needs a newer version of gotodo", version)
+	case version == 1:
+		if err := migrateBoltV1(b); err != nil {
+			return err
+		}
+	}
+
+	if bytes.Equal(b.Get(boltSchemaKey), []byte(strconv.Itoa(boltSchemaVersion))) {
+		return nil
+	}
+
+	return b.Put(boltSchemaKey, []byte(strconv.Itoa(boltSchemaVersion)))
+}
+
+// migrateBoltV1 rewrites todo.txt strings keyed by decimal IDs as records keyed by big-endian
+// IDs. Migrated todos are marked modified at the time of the migration. The bucket sequence is
+// left alone, so the IDs of deleted todos still aren't reused.
+func migrateBoltV1(b *bolt.Bucket) error {
+	type legacyTodo struct {
+		key   []byte
+		value []byte
+	}
+
+	legacy := make([]legacyTodo, 0)
+	err := b.ForEach(func(k, v []byte) error {
+		legacy = append(legacy, legacyTodo{key: append([]byte{}, k...), value: append([]byte{}, v...)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, item := range legacy {
+		todoID, err := strconv.Atoi(string(item.key))
+		if err != nil {
+			return fmt.Errorf("Can't migrate key \"%s\", it isn't a todo ID", item.key)
+		}
+
+		value, err := encodeBoltRecord(todoID, FromString(string(item.value)), now)
+		if err != nil {
+			return err
+		}
+
+		if err := b.Delete(item.key); err != nil {
+			return err
+		}
+		if err := b.Put(boltKey(todoID), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package gotodo
+
+import "errors"
+
+// BulkResult is the outcome of a bulk operation for a single Todo. Err is set when the Todo could
+// not be changed.
+type BulkResult struct {
+	TodoID int
+	Err    error
+}
+
+// ErrBulkFailed is returned by bulk operations when some of their todos could not be changed.
+// The whole batch is discarded, so none of the todos are changed.
+var ErrBulkFailed = errors.New("Some todos could not be changed, no changes were saved")
+
+// bulk runs fn for every Todo identified by todoIDs as a single operation and batch, skipping
+// repeated IDs. Every Todo is attempted so the results report all of the failures.
+func (tm *TodoManager) bulk(name string, todoIDs []int, fn func(op *operation, todoID int) error) ([]BulkResult, error) {
+	results := make([]BulkResult, 0, len(todoIDs))
+
+	err := tm.batch(name, func(op *operation) error {
+		failed := false
+		seen := make(map[int]void)
+
+		for _, todoID := range todoIDs {
+			if _, ok := seen[todoID]; ok {
+				continue
+			}
+			seen[todoID] = void{}
+
+			err := fn(op, todoID)
+			if err != nil {
+				failed = true
+			}
+			results = append(results, BulkResult{TodoID: todoID, Err: err})
+		}
+
+		if failed {
+			return ErrBulkFailed
+		}
+
+		return nil
+	})
+
+	return results, err
+}
+
+// singleResult returns the error of a bulk operation run on a single Todo
+func singleResult(results []BulkResult, err error) error {
+	if len(results) == 1 && results[0].Err != nil {
+		return results[0].Err
+	}
+
+	return err
+}
@@ -0,0 +1,63 @@
+package gotodo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteAll(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+
+	results, err := todoManager.CompleteAll([]int{1, 2, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []BulkResult{{TodoID: 1}, {TodoID: 2}}, results)
+
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, true, items[0].Complete)
+	assert.Equal(t, true, items[1].Complete)
+
+	// the whole batch is a single journal entry
+	entries, _, err := todoManager.Journal.History()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, 2, len(entries[0].Changes))
+}
+
+func TestBulkFailure(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+	original := readTestFile(t, storage)
+
+	results, err := todoManager.PrioritizeAll([]int{1, 5, 2}, "C")
+	assert.Equal(t, ErrBulkFailed, err)
+	assert.Equal(t, []BulkResult{{TodoID: 1}, {TodoID: 5, Err: ErrNotFound}, {TodoID: 2}}, results)
+
+	// nothing is saved when any todo fails
+	assert.Equal(t, original, readTestFile(t, storage))
+	entries, _, err := todoManager.Journal.History()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+}
+
+func TestDeleteAll(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+
+	results, err := todoManager.DeleteAll([]int{2, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "\n\n", readTestFile(t, storage))
+
+	_, err = todoManager.Undo(1)
+	assert.NoError(t, err)
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+}
+
+func TestSingleResult(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, journalTodos, withTestJournal(0))
+
+	assert.Equal(t, ErrNotFound, todoManager.Complete(5))
+	assert.Equal(t, ErrNotFound, todoManager.AddProject(5, "gotodo"))
+}
@@ -6,8 +6,10 @@ const TimeFormat = "2006-01-02"
 // ListPending is all active todos
 // ListAll is all todos, active and complete
 // ListDone is all completed todos
+// ListArchived is all todos moved to the archive
 const (
 	ListPending = iota
 	ListAll
 	ListDone
+	ListArchived
 )
@@ -0,0 +1,122 @@
+package gotodo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateHint describes the dates ParseDate accepts, for error messages
+const dateHint = "use YYYY-MM-DD or a day like tomorrow, fri or +3d"
+
+// weekdays are the full names of the days of the week, indexed by time.Weekday
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// ParseDate converts a date as a person types it into a date, counting from the day of now. Along
+// with YYYY-MM-DD, it accepts:
+//
+//	today, tomorrow, yesterday
+//	a weekday such as fri or friday, for the next one after today
+//	+3d, +2w, +1m, +1y or +5b, counting days, weeks, months, years or business days from today
+//	eow, eom, eoy for the last day of this week, month or year
+//	next-week, next-month, next-year for the first day of the next week, month or year
+//
+// Weeks run from Monday to Sunday.
+func ParseDate(value string, now time.Time) (time.Time, error) {
+	if date := parseDate(value); date.Valid {
+		return date.Time, nil
+	}
+
+	today := dateOf(now)
+	// days until Sunday, the last day of the week
+	toSunday := (7 - int(today.Weekday())) % 7
+
+	word := strings.ToLower(value)
+	switch word {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "eow":
+		return today.AddDate(0, 0, toSunday), nil
+	case "eom":
+		return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, time.UTC), nil
+	case "eoy":
+		return time.Date(today.Year(), time.December, 31, 0, 0, 0, 0, time.UTC), nil
+	case "next-week":
+		return today.AddDate(0, 0, toSunday+1), nil
+	case "next-month":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, time.UTC), nil
+	case "next-year":
+		return time.Date(today.Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	// Weekdays can be shortened to their first three letters or more, like thu or thurs
+	if len(word) >= 3 {
+		for day, name := range weekdays {
+			if strings.HasPrefix(name, word) {
+				days := (day - int(today.Weekday()) + 7) % 7
+				if days == 0 {
+					days = 7
+				}
+				return today.AddDate(0, 0, days), nil
+			}
+		}
+	}
+
+	// +3d is a strict recurrence, counted from today
+	if rec, err := ParseRecurrence(word); err == nil && rec.Strict {
+		return rec.Next(today), nil
+	}
+
+	return time.Time{}, fmt.Errorf("Invalid date \"%s\", %s", value, dateHint)
+}
+
+// normalizeDates replaces due: dates that ParseDate understands but aren't YYYY-MM-DD, such as
+// due:tomorrow, with the dates they stand for on the day of now. Other words are left as written.
+func (t *Todo) normalizeDates(now time.Time) {
+	changed := false
+	description := rewriteWords(t.Description, func(word string) string {
+		if !strings.HasPrefix(word, "due:") || parseDate(word[len("due:"):]).Valid {
+			return word
+		}
+
+		date, err := ParseDate(word[len("due:"):], now)
+		if err != nil {
+			return word
+		}
+
+		changed = true
+		return "due:" + date.Format(TimeFormat)
+	})
+
+	if changed {
+		t.setDescription(description)
+	}
+}
+
+// SetDue sets the due date of a Todo to a date accepted by ParseDate and returns it. The due:
+// attribute in the description is replaced, or added if the todo has none.
+func (tm *TodoManager) SetDue(todoID int, when string) (time.Time, error) {
+	date, err := ParseDate(when, time.Now())
+	if err != nil {
+		return date, err
+	}
+
+	return date, tm.modify("due", todoID, func(todo *Todo) error {
+		todo.setAttribute("due", date.Format(TimeFormat))
+		return nil
+	})
+}
+
+// ClearDue removes the due date of a Todo along with its due: attribute
+func (tm *TodoManager) ClearDue(todoID int) error {
+	return tm.modify("due", todoID, func(todo *Todo) error {
+		return removeWords(todo, errors.New("Todo has no due date"), func(word string) bool {
+			return attributeKey(word) == "due"
+		})
+	})
+}
@@ -0,0 +1,144 @@
+package gotodo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRelativeDate(t *testing.T) {
+	// 2020-04-29 is a Wednesday
+	now := time.Date(2020, time.April, 29, 15, 30, 0, 0, time.Local)
+
+	for value, expected := range map[string]string{
+		"2020-06-01": "2020-06-01",
+		"today":      "2020-04-29",
+		"Tomorrow":   "2020-04-30",
+		"yesterday":  "2020-04-28",
+		"fri":        "2020-05-01",
+		"friday":     "2020-05-01",
+		"thurs":      "2020-04-30",
+		"wed":        "2020-05-06",
+		"mon":        "2020-05-04",
+		"+3d":        "2020-05-02",
+		"+2w":        "2020-05-13",
+		"+1m":        "2020-05-29",
+		"+2b":        "2020-05-01",
+		"eow":        "2020-05-03",
+		"eom":        "2020-04-30",
+		"eoy":        "2020-12-31",
+		"next-week":  "2020-05-04",
+		"next-month": "2020-05-01",
+		"next-year":  "2021-01-01",
+	} {
+		date, err := ParseDate(value, now)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, date.Format(TimeFormat), value)
+	}
+
+	// on a Sunday, the end of the week is today and the next week starts tomorrow
+	sunday := time.Date(2020, time.May, 3, 9, 0, 0, 0, time.Local)
+	date, err := ParseDate("eow", sunday)
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-05-03", date.Format(TimeFormat))
+	date, err = ParseDate("next-week", sunday)
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-05-04", date.Format(TimeFormat))
+
+	// the end of January is followed by the first of February, not March
+	january := time.Date(2020, time.January, 31, 9, 0, 0, 0, time.Local)
+	date, err = ParseDate("next-month", january)
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-02-01", date.Format(TimeFormat))
+	date, err = ParseDate("eom", january)
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-01-31", date.Format(TimeFormat))
+}
+
+func TestParseRelativeDateInvalid(t *testing.T) {
+	now := time.Now()
+
+	for _, value := range []string{"", "someday", "fr", "3d", "+0d", "-1d", "2020-13-01", "next-decade"} {
+		_, err := ParseDate(value, now)
+		assert.Error(t, err, value)
+	}
+
+	_, err := ParseDate("someday", now)
+	assert.EqualError(t, err, "Invalid date \"someday\", use YYYY-MM-DD or a day like tomorrow, fri or +3d")
+}
+
+func TestNormalizeDates(t *testing.T) {
+	now := time.Date(2020, time.April, 29, 15, 30, 0, 0, time.Local)
+
+	todo := FromString("(A) Call  mom due:tomorrow @phone")
+	todo.normalizeDates(now)
+	assert.Equal(t, "(A) Call  mom due:2020-04-30 @phone", todo.String())
+	assert.Equal(t, "2020-04-30", todo.DueDate.Display())
+	assert.Equal(t, "2020-04-30", todo.Attributes["due"])
+
+	// dates that are already absolute, or aren't dates, are left alone
+	for _, todoStr := range []string{"Call mom due:2020-05-01", "Call mom due:someday", "Call mom t:tomorrow"} {
+		todo = FromString(todoStr)
+		todo.normalizeDates(now)
+		assert.Equal(t, todoStr, todo.String())
+	}
+}
+
+func TestRelativeDueDates(t *testing.T) {
+	now := time.Now()
+	tomorrow, err := ParseDate("tomorrow", now)
+	assert.NoError(t, err)
+	friday, err := ParseDate("fri", now)
+	assert.NoError(t, err)
+
+	storage := getTestFileStorage(t, "")
+	todoManager := NewTodoManager(WithFileStorage(storage.Path))
+
+	_, err = todoManager.Add("Call mom due:tomorrow")
+	assert.NoError(t, err)
+	_, err = todoManager.Add("Write report")
+	assert.NoError(t, err)
+	assert.NoError(t, todoManager.Append(2, "due:fri"))
+
+	assert.NoError(t, ValidateTodo("Call mom due:+3d"))
+	assert.EqualError(t, ValidateTodo("Call mom due:someday"), "Invalid due date \"someday\", use YYYY-MM-DD or a day like tomorrow, fri or +3d")
+
+	assert.Equal(t, strings.Join([]string{
+		"Call mom due:" + tomorrow.Format(TimeFormat),
+		"Write report due:" + friday.Format(TimeFormat),
+	}, "\n")+"\n", readTestFile(t, storage))
+}
+
+func TestSetDue(t *testing.T) {
+	storage := getTestFileStorage(t, "Call mom due:2020-05-01 @phone\nWrite report\n")
+	todoManager := NewTodoManager(WithFileStorage(storage.Path))
+
+	date, err := todoManager.SetDue(1, "2020-06-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-06-01", date.Format(TimeFormat))
+
+	date, err = todoManager.SetDue(2, "eom")
+	assert.NoError(t, err)
+	expected, _ := ParseDate("eom", time.Now())
+	assert.Equal(t, expected, date)
+
+	_, err = todoManager.SetDue(2, "someday")
+	assert.Error(t, err)
+	_, err = todoManager.SetDue(9, "tomorrow")
+	assert.Equal(t, ErrNotFound, err)
+
+	todo, err := storage.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Write report due:"+expected.Format(TimeFormat), todo.String())
+	assert.Equal(t, expected.Format(TimeFormat), todo.DueDate.Display())
+
+	assert.NoError(t, todoManager.ClearDue(1))
+	assert.EqualError(t, todoManager.ClearDue(1), "Todo has no due date")
+
+	todo, err = storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Call mom @phone", todo.String())
+	assert.Equal(t, false, todo.DueDate.Valid)
+}
@@ -0,0 +1,197 @@
+package gotodo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TodoEdit is a change made to a list of todos in an editor. A TodoID of 0 adds Todo as a new
+// Todo, and an empty Todo removes the Todo identified by TodoID.
+type TodoEdit struct {
+	TodoID int
+	Todo   string
+}
+
+// EditError lists every problem found in edited todos, so they can all be fixed at once
+type EditError struct {
+	Problems []string
+}
+
+func (e *EditError) Error() string {
+	return "Can't apply the edits:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// ErrEmptyEdit is returned for an edit list left without any todos. Like an empty commit message,
+// it aborts the edit rather than removing every todo.
+var ErrEmptyEdit = errors.New("No todos left in the edit list, leaving them unchanged")
+
+// editLinePattern matches a line of an edit list, capturing the todo ID and the todo
+var editLinePattern = regexp.MustCompile(`^\s*\[(\d+)\]\s*(.*)$`)
+
+// FormatEditList writes todos one per line with their IDs in brackets, like "[3] (A) Call mom",
+// after a comment explaining how to edit them
+func FormatEditList(items TodoList) string {
+	var list strings.Builder
+
+	list.WriteString("# Change a todo by editing its line, remove it by deleting the line, and add\n")
+	list.WriteString("# one with a new line without an ID. Lines starting with # are ignored.\n")
+	for _, todo := range items {
+		fmt.Fprintf(&list, "[%d] %s\n", todo.TodoID, todo.String())
+	}
+
+	return list.String()
+}
+
+// ParseEditList compares an edit list written by FormatEditList for items with the original
+// items and returns the edits made to it: changed, added and removed todos, in that order.
+// Every line is validated, and an *EditError describes all of the problems found. A list without
+// any todo lines returns ErrEmptyEdit.
+func ParseEditList(items TodoList, list string) ([]TodoEdit, error) {
+	original := make(map[int]*Todo)
+	for _, todo := range items {
+		original[todo.TodoID] = todo
+	}
+
+	changed := make([]TodoEdit, 0)
+	added := make([]TodoEdit, 0)
+	seen := make(map[int]int)
+	problems := make([]string, 0)
+
+	for i, line := range strings.Split(list, "\n") {
+		lineNum := i + 1
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		match := editLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			if err := ValidateTodo(line); err != nil {
+				problems = append(problems, fmt.Sprintf("Line %d: %s", lineNum, err))
+				continue
+			}
+			added = append(added, TodoEdit{Todo: strings.TrimSpace(line)})
+			continue
+		}
+
+		todoID, _ := strconv.Atoi(match[1])
+		todo, ok := original[todoID]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("Line %d: Todo ID %d wasn't opened for editing", lineNum, todoID))
+			continue
+		}
+		if previous, ok := seen[todoID]; ok {
+			problems = append(problems, fmt.Sprintf("Line %d: Todo ID %d is already on line %d", lineNum, todoID, previous))
+			continue
+		}
+		seen[todoID] = lineNum
+
+		if err := ValidateTodo(match[2]); err != nil {
+			problems = append(problems, fmt.Sprintf("Line %d: %s", lineNum, err))
+			continue
+		}
+
+		// The edited line lost its trailing whitespace, so the stored line mustn't count it either
+		if match[2] != strings.TrimRight(todo.String(), " \t\r") {
+			changed = append(changed, TodoEdit{TodoID: todoID, Todo: match[2]})
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &EditError{Problems: problems}
+	}
+	if len(items) > 0 && len(seen) == 0 && len(added) == 0 {
+		return nil, ErrEmptyEdit
+	}
+
+	// Todos whose lines are gone were deleted
+	removed := make([]TodoEdit, 0)
+	for todoID := range original {
+		if _, ok := seen[todoID]; !ok {
+			removed = append(removed, TodoEdit{TodoID: todoID})
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].TodoID < removed[j].TodoID
+	})
+
+	edits := append(changed, added...)
+	return append(edits, removed...), nil
+}
+
+// ValidateTodo checks that a todo.txt string has a description and that its due:, t: and rec:
+// attributes can be used. due: may be any date ParseDate accepts.
+func ValidateTodo(todoStr string) error {
+	todo := FromString(todoStr)
+
+	if strings.TrimSpace(todo.Description) == "" {
+		return errors.New("Todo has no description")
+	}
+
+	// Relative due dates like due:tomorrow are made absolute when the todo is saved
+	if value, ok := todo.Attributes["due"]; ok && !todo.DueDate.Valid {
+		if _, err := ParseDate(value, time.Now()); err != nil {
+			return fmt.Errorf("Invalid due date \"%s\", %s", value, dateHint)
+		}
+	}
+
+	if value, ok := todo.Attributes["t"]; ok && !todo.ThresholdDate.Valid {
+		return fmt.Errorf("Invalid threshold date \"%s\", use YYYY-MM-DD", value)
+	}
+
+	if value, ok := todo.Attributes["rec"]; ok {
+		if _, err := ParseRecurrence(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplyEdits makes every edit in a single batch, as one operation that can be undone at once. The
+// result of an added todo holds its new ID. If any edit fails, none of them are saved.
+func (tm *TodoManager) ApplyEdits(edits []TodoEdit) ([]BulkResult, error) {
+	results := make([]BulkResult, 0, len(edits))
+
+	err := tm.batch("edit", func(op *operation) error {
+		failed := false
+
+		for _, edit := range edits {
+			var err error
+			todoID := edit.TodoID
+
+			switch {
+			case todoID == 0:
+				todo := FromString(edit.Todo)
+				err = op.create(todo)
+				todoID = todo.TodoID
+			case edit.Todo == "":
+				err = op.delete(todoID)
+			default:
+				err = op.modify(todoID, func(todo *Todo) error {
+					*todo = *FromString(edit.Todo)
+					todo.TodoID = todoID
+					return nil
+				})
+			}
+
+			if err != nil {
+				failed = true
+			}
+			results = append(results, BulkResult{TodoID: todoID, Err: err})
+		}
+
+		if failed {
+			return ErrBulkFailed
+		}
+
+		return nil
+	})
+
+	return results, err
+}
@@ -0,0 +1,126 @@
+package gotodo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatEditList(t *testing.T) {
+	items := TodoList{FromString("(A) Call mom"), FromString("x  Keep  my spacing")}
+	items[0].TodoID = 3
+	items[1].TodoID = 7
+
+	list := FormatEditList(items)
+	assert.Equal(t, true, strings.HasSuffix(list, "\n[3] (A) Call mom\n[7] x  Keep  my spacing\n"))
+
+	// an untouched list has no edits
+	edits, err := ParseEditList(items, list)
+	assert.NoError(t, err)
+	assert.Equal(t, []TodoEdit{}, edits)
+}
+
+func TestParseEditListTrailingSpace(t *testing.T) {
+	items := TodoList{FromString("Buy milk  "), FromString("Pay rent\t")}
+	items[0].TodoID = 1
+	items[1].TodoID = 2
+
+	// Editors strip trailing whitespace, which isn't an edit
+	edits, err := ParseEditList(items, "[1] Buy milk\n[2] Pay rent\n")
+	assert.NoError(t, err)
+	assert.Equal(t, []TodoEdit{}, edits)
+
+	edits, err = ParseEditList(items, FormatEditList(items))
+	assert.NoError(t, err)
+	assert.Equal(t, []TodoEdit{}, edits)
+}
+
+func TestParseEditList(t *testing.T) {
+	items := TodoList{FromString("(A) Call mom"), FromString("Buy milk"), FromString("Pay rent due:2020-06-01")}
+	for i, todo := range items {
+		todo.TodoID = i + 1
+	}
+
+	edits, err := ParseEditList(items, strings.Join([]string{
+		"# a comment",
+		"Water plants @home",
+		"[3] Pay rent due:2020-07-01",
+		"",
+		"  [1] (A) Call mom",
+	}, "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []TodoEdit{
+		{TodoID: 3, Todo: "Pay rent due:2020-07-01"},
+		{Todo: "Water plants @home"},
+		{TodoID: 2},
+	}, edits)
+}
+
+func TestParseEditListEmpty(t *testing.T) {
+	items := TodoList{FromString("(A) Call mom"), FromString("Buy milk")}
+	items[0].TodoID = 1
+	items[1].TodoID = 2
+
+	// Clearing the list aborts the edit instead of removing everything
+	_, err := ParseEditList(items, "")
+	assert.Equal(t, ErrEmptyEdit, err)
+	_, err = ParseEditList(items, "# Edit the todos below\n\n")
+	assert.Equal(t, ErrEmptyEdit, err)
+
+	edits, err := ParseEditList(items, "[2] Buy milk\n")
+	assert.NoError(t, err)
+	assert.Equal(t, []TodoEdit{{TodoID: 1}}, edits)
+
+	edits, err = ParseEditList(TodoList{}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []TodoEdit{}, edits)
+}
+
+func TestParseEditListProblems(t *testing.T) {
+	items := TodoList{FromString("(A) Call mom"), FromString("Buy milk")}
+	items[0].TodoID = 1
+	items[1].TodoID = 2
+
+	_, err := ParseEditList(items, strings.Join([]string{
+		"[1] (A) Call mom rec:often",
+		"[3] Unknown todo",
+		"[1] Call mom again",
+		"[2]",
+		"Pay rent t:tomorrow",
+	}, "\n"))
+	assert.EqualError(t, err, strings.Join([]string{
+		"Can't apply the edits:",
+		"  Line 1: Invalid recurrence \"often\", use a number and unit like 1w or +3d",
+		"  Line 2: Todo ID 3 wasn't opened for editing",
+		"  Line 3: Todo ID 1 is already on line 1",
+		"  Line 4: Todo has no description",
+		"  Line 5: Invalid threshold date \"tomorrow\", use YYYY-MM-DD",
+	}, "\n"))
+}
+
+func TestApplyEdits(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+
+	results, err := todoManager.ApplyEdits([]TodoEdit{
+		{TodoID: 2, Todo: "(C) Add parser test +gotodo"},
+		{Todo: "Add edit test +gotodo"},
+		{TodoID: 1},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []BulkResult{{TodoID: 2}, {TodoID: 3}, {TodoID: 1}}, results)
+	assert.Equal(t, "\n(C) Add parser test +gotodo\nAdd edit test +gotodo\n", readTestFile(t, storage))
+
+	// every edit is undone at once
+	entries, err := todoManager.Undo(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "edit", entries[0].Operation)
+	assert.Equal(t, 3, len(entries[0].Changes))
+
+	// nothing is saved when an edit fails
+	original := readTestFile(t, storage)
+	results, err = todoManager.ApplyEdits([]TodoEdit{{TodoID: 2, Todo: "Changed"}, {TodoID: 9}})
+	assert.Equal(t, ErrBulkFailed, err)
+	assert.Equal(t, ErrNotFound, results[1].Err)
+	assert.Equal(t, original, readTestFile(t, storage))
+}
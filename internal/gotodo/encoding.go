@@ -0,0 +1,95 @@
+package gotodo
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// todoRecord is the JSON and YAML representation of a Todo
+type todoRecord struct {
+	TodoID         int               `json:"id" yaml:"id"`
+	Complete       bool              `json:"complete" yaml:"complete"`
+	Priority       string            `json:"priority" yaml:"priority"`
+	CompletionDate NullTime          `json:"completion_date" yaml:"completion_date"`
+	CreationDate   NullTime          `json:"creation_date" yaml:"creation_date"`
+	DueDate        NullTime          `json:"due_date" yaml:"due_date"`
+	ThresholdDate  NullTime          `json:"threshold_date" yaml:"threshold_date"`
+	Description    string            `json:"description" yaml:"description"`
+	Projects       Tags              `json:"projects" yaml:"projects"`
+	Contexts       Tags              `json:"contexts" yaml:"contexts"`
+	Attributes     map[string]string `json:"attributes" yaml:"attributes"`
+	Text           string            `json:"text" yaml:"text"`
+}
+
+// record converts a Todo into its JSON and YAML representation
+func (t *Todo) record() todoRecord {
+	attributes := make(map[string]string)
+	for key, value := range t.Attributes {
+		attributes[key] = value
+	}
+
+	return todoRecord{
+		TodoID:         t.TodoID,
+		Complete:       t.Complete,
+		Priority:       PriorityString(t.Priority),
+		CompletionDate: t.CompletionDate,
+		CreationDate:   t.CreationDate,
+		DueDate:        t.DueDate,
+		ThresholdDate:  t.ThresholdDate,
+		Description:    t.Description,
+		Projects:       t.Projects,
+		Contexts:       t.Contexts,
+		Attributes:     attributes,
+		Text:           t.String(),
+	}
+}
+
+// MarshalJSON encodes a Todo as a JSON object, with the priority as letters and dates as
+// YYYY-MM-DD strings
+func (t *Todo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.record())
+}
+
+// MarshalYAML encodes a Todo as a YAML mapping with the same fields as MarshalJSON
+func (t *Todo) MarshalYAML() (interface{}, error) {
+	return t.record(), nil
+}
+
+// MarshalJSON encodes a NullTime as a YYYY-MM-DD string, or null when it is not valid
+func (me NullTime) MarshalJSON() ([]byte, error) {
+	if !me.Valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(me.Display())
+}
+
+// MarshalYAML encodes a NullTime as a YYYY-MM-DD string, or null when it is not valid
+func (me NullTime) MarshalYAML() (interface{}, error) {
+	if !me.Valid {
+		return nil, nil
+	}
+
+	return me.Display(), nil
+}
+
+// Sorted returns the tags in alphabetical order
+func (me Tags) Sorted() []string {
+	tags := make([]string, 0, len(me))
+	for tag := range me {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+// MarshalJSON encodes Tags as a sorted list of strings
+func (me Tags) MarshalJSON() ([]byte, error) {
+	return json.Marshal(me.Sorted())
+}
+
+// MarshalYAML encodes Tags as a sorted list of strings
+func (me Tags) MarshalYAML() (interface{}, error) {
+	return me.Sorted(), nil
+}
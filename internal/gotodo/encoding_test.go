@@ -0,0 +1,72 @@
+package gotodo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	todo := FromString("(B) 2020-04-28 Work on unit tests @codehealth +gotodo +cli due:2020-05-01")
+	todo.TodoID = 3
+
+	encoded, err := json.Marshal(todo)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 3,
+		"complete": false,
+		"priority": "B",
+		"completion_date": null,
+		"creation_date": "2020-04-28",
+		"due_date": "2020-05-01",
+		"threshold_date": null,
+		"description": "Work on unit tests @codehealth +gotodo +cli due:2020-05-01",
+		"projects": ["cli", "gotodo"],
+		"contexts": ["codehealth"],
+		"attributes": {"due": "2020-05-01"},
+		"text": "(B) 2020-04-28 Work on unit tests @codehealth +gotodo +cli due:2020-05-01"
+	}`, string(encoded))
+}
+
+func TestMarshalJSONEmpty(t *testing.T) {
+	encoded, err := json.Marshal(&Todo{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 0,
+		"complete": false,
+		"priority": "",
+		"completion_date": null,
+		"creation_date": null,
+		"due_date": null,
+		"threshold_date": null,
+		"description": "",
+		"projects": [],
+		"contexts": [],
+		"attributes": {},
+		"text": ""
+	}`, string(encoded))
+}
+
+func TestMarshalYAML(t *testing.T) {
+	todo := FromString("x 2020-04-29 2020-04-28 Add parser test +gotodo")
+	todo.TodoID = 2
+
+	encoded, err := yaml.Marshal(todo)
+	assert.NoError(t, err)
+	assert.Equal(t, `id: 2
+complete: true
+priority: ""
+completion_date: "2020-04-29"
+creation_date: "2020-04-28"
+due_date: null
+threshold_date: null
+description: Add parser test +gotodo
+projects:
+- gotodo
+contexts: []
+attributes: {}
+text: x 2020-04-29 2020-04-28 Add parser test +gotodo
+`, string(encoded))
+}
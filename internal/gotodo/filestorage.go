@@ -0,0 +1,256 @@
+package gotodo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// FileStorage implements Storage, saving items to a todo.txt file with one todo per line.
+// Like todo.sh, a todo's ID is its line number. Deleted todos leave a blank line behind so the
+// IDs of the remaining todos never change.
+type FileStorage struct {
+	Path string
+}
+
+// getPath returns the absolute path of the todo.txt file
+func (me *FileStorage) getPath() (string, error) {
+	return homedir.Expand(me.Path)
+}
+
+// readLines returns every line in the todo.txt file. A missing file has no lines.
+func (me *FileStorage) readLines() ([]string, error) {
+	path, err := me.getPath()
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make([]string, 0), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// A file holding just a newline is the blank line left by deleting its only todo
+	if len(contents) == 0 {
+		return make([]string, 0), nil
+	}
+
+	text := strings.TrimSuffix(strings.ReplaceAll(string(contents), "\r\n", "\n"), "\n")
+	return strings.Split(text, "\n"), nil
+}
+
+// writeLines replaces the contents of the todo.txt file
+func (me *FileStorage) writeLines(lines []string) error {
+	path, err := me.getPath()
+	if err != nil {
+		return err
+	}
+
+	contents := ""
+	if len(lines) > 0 {
+		contents = strings.Join(lines, "\n") + "\n"
+	}
+
+	return replaceFile(path, []byte(contents), 0644)
+}
+
+// replaceFile replaces the contents of a file, creating it with mode if it doesn't exist. The
+// file is written next to the original and renamed into place so a partial write never clobbers
+// its old contents.
+func replaceFile(path string, contents []byte, mode os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(contents); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	// keep the permissions of an existing file, since it may be shared with other tools
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode()
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// update runs fn on the lines of the todo.txt file and writes them back if fn succeeds
+func (me *FileStorage) update(fn func(tx *fileLines) error) error {
+	lines, err := me.readLines()
+	if err != nil {
+		return err
+	}
+
+	tx := &fileLines{lines: lines}
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return me.writeLines(tx.lines)
+}
+
+// Batch runs fn with a Storage holding the todo.txt file in memory. The changes fn makes are
+// written to the file all at once if it succeeds, and discarded if it returns an error.
+func (me *FileStorage) Batch(fn func(tx Storage) error) error {
+	return me.update(func(tx *fileLines) error {
+		return fn(tx)
+	})
+}
+
+// Create appends a new *Todo to the end of the file
+func (me *FileStorage) Create(todo *Todo) error {
+	return me.update(func(tx *fileLines) error {
+		return tx.Create(todo)
+	})
+}
+
+// Get retrieves the *Todo identified by todoID
+func (me *FileStorage) Get(todoID int) (*Todo, error) {
+	lines, err := me.readLines()
+	if err != nil {
+		return nil, err
+	}
+
+	return (&fileLines{lines: lines}).Get(todoID)
+}
+
+// List reads all Todos, skipping blank lines
+func (me *FileStorage) List() (TodoList, error) {
+	lines, err := me.readLines()
+	if err != nil {
+		return make(TodoList, 0), err
+	}
+
+	return (&fileLines{lines: lines}).List()
+}
+
+// Update modifies the *Todo identified by todoID
+func (me *FileStorage) Update(todoID int, todo *Todo) error {
+	return me.update(func(tx *fileLines) error {
+		return tx.Update(todoID, todo)
+	})
+}
+
+// Delete removes the *Todo identified by todoID, leaving its line blank
+func (me *FileStorage) Delete(todoID int) error {
+	return me.update(func(tx *fileLines) error {
+		return tx.Delete(todoID)
+	})
+}
+
+// Restore recreates a deleted *Todo on its original line
+func (me *FileStorage) Restore(todo *Todo) error {
+	return me.update(func(tx *fileLines) error {
+		return tx.Restore(todo)
+	})
+}
+
+// fileLines implements Storage over the lines of a todo.txt file held in memory
+type fileLines struct {
+	lines []string
+}
+
+// checkLine checks that todoID refers to a non-blank line.
+func (me *fileLines) checkLine(todoID int) error {
+	if todoID < 1 || todoID > len(me.lines) || strings.TrimSpace(me.lines[todoID-1]) == "" {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Create appends a new *Todo after the last line
+func (me *fileLines) Create(todo *Todo) error {
+	me.lines = append(me.lines, todo.String())
+	todo.TodoID = len(me.lines)
+
+	return nil
+}
+
+// Get retrieves the *Todo identified by todoID
+func (me *fileLines) Get(todoID int) (*Todo, error) {
+	err := me.checkLine(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := FromString(me.lines[todoID-1])
+	todo.TodoID = todoID
+
+	return todo, nil
+}
+
+// List reads all Todos, skipping blank lines
+func (me *fileLines) List() (TodoList, error) {
+	items := make(TodoList, 0)
+
+	for idx, line := range me.lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		todo := FromString(line)
+		todo.TodoID = idx + 1
+		items = append(items, todo)
+	}
+
+	return items, nil
+}
+
+// Update modifies the *Todo identified by todoID
+func (me *fileLines) Update(todoID int, todo *Todo) error {
+	// make sure the line exists before working with it
+	err := me.checkLine(todoID)
+	if err != nil {
+		return err
+	}
+
+	me.lines[todoID-1] = todo.String()
+
+	return nil
+}
+
+// Delete removes the *Todo identified by todoID, leaving its line blank
+func (me *fileLines) Delete(todoID int) error {
+	// make sure the line exists before working with it
+	err := me.checkLine(todoID)
+	if err != nil {
+		return err
+	}
+
+	me.lines[todoID-1] = ""
+
+	return nil
+}
+
+// Restore recreates a deleted *Todo on its original line
+func (me *fileLines) Restore(todo *Todo) error {
+	// make sure the line is free before working with it
+	if me.checkLine(todo.TodoID) == nil {
+		return errExists
+	} else if todo.TodoID < 1 {
+		return ErrNotFound
+	}
+
+	for len(me.lines) < todo.TodoID {
+		me.lines = append(me.lines, "")
+	}
+	me.lines[todo.TodoID-1] = todo.String()
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package gotodo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestFileStorage(t *testing.T, contents string) *FileStorage {
+	dir, err := ioutil.TempDir("", "gotodo")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "todo.txt")
+	if contents != "" {
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+
+	return &FileStorage{Path: path}
+}
+
+func readTestFile(t *testing.T, storage *FileStorage) string {
+	contents, err := ioutil.ReadFile(storage.Path)
+	assert.NoError(t, err)
+	return string(contents)
+}
+
+func TestFileStorageList(t *testing.T) {
+	storage := getTestFileStorage(t, "(B) 2020-04-28 Work on unit tests\n\nx 2020-04-29 Add parser test\n")
+
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, 1, items[0].TodoID)
+	assert.Equal(t, "Work on unit tests", items[0].Description)
+	assert.Equal(t, 3, items[1].TodoID)
+	assert.Equal(t, true, items[1].Complete)
+}
+
+func TestFileStorageListMissingFile(t *testing.T) {
+	storage := getTestFileStorage(t, "")
+
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+}
+
+func TestFileStorageCreate(t *testing.T) {
+	storage := getTestFileStorage(t, "First todo\r\nSecond todo")
+
+	todo := FromString("(A) Third todo +gotodo")
+	assert.NoError(t, storage.Create(todo))
+	assert.Equal(t, 3, todo.TodoID)
+	assert.Equal(t, "First todo\nSecond todo\n(A) Third todo +gotodo\n", readTestFile(t, storage))
+}
+
+func TestFileStorageGet(t *testing.T) {
+	storage := getTestFileStorage(t, "First todo\n\nThird todo\n")
+
+	todo, err := storage.Get(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, todo.TodoID)
+	assert.Equal(t, "Third todo", todo.Description)
+
+	_, err = storage.Get(2)
+	assert.Equal(t, ErrNotFound, err)
+	_, err = storage.Get(0)
+	assert.Equal(t, ErrNotFound, err)
+	_, err = storage.Get(4)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestFileStorageUpdate(t *testing.T) {
+	storage := getTestFileStorage(t, "First todo\nSecond todo\n")
+
+	assert.NoError(t, storage.Update(2, FromString("(C) Second todo, updated")))
+	assert.Equal(t, "First todo\n(C) Second todo, updated\n", readTestFile(t, storage))
+
+	assert.Equal(t, ErrNotFound, storage.Update(3, FromString("Missing todo")))
+}
+
+func TestFileStorageDelete(t *testing.T) {
+	storage := getTestFileStorage(t, "First todo\nSecond todo\nThird todo\n")
+
+	assert.NoError(t, storage.Delete(2))
+	assert.Equal(t, "First todo\n\nThird todo\n", readTestFile(t, storage))
+	assert.Equal(t, ErrNotFound, storage.Delete(2))
+
+	// IDs of the remaining todos stay the same and new todos never reuse a deleted line
+	todo, err := storage.Get(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Third todo", todo.Description)
+
+	assert.NoError(t, storage.Delete(3))
+	todo = FromString("Fourth todo")
+	assert.NoError(t, storage.Create(todo))
+	assert.Equal(t, 4, todo.TodoID)
+}
+
+func TestFileStorageDeleteOnly(t *testing.T) {
+	storage := getTestFileStorage(t, "First todo\n")
+
+	// Deleting the only todo leaves its blank line, so its ID isn't reused
+	assert.NoError(t, storage.Delete(1))
+	assert.Equal(t, "\n", readTestFile(t, storage))
+
+	todo := FromString("Second todo")
+	assert.NoError(t, storage.Create(todo))
+	assert.Equal(t, 2, todo.TodoID)
+	assert.Equal(t, "\nSecond todo\n", readTestFile(t, storage))
+}
+
+func TestFileStorageRestore(t *testing.T) {
+	storage := getTestFileStorage(t, "First todo\n\nThird todo\n")
+
+	todo := FromString("Second todo")
+	todo.TodoID = 2
+	assert.NoError(t, storage.Restore(todo))
+	assert.Equal(t, "First todo\nSecond todo\nThird todo\n", readTestFile(t, storage))
+
+	assert.Equal(t, errExists, storage.Restore(todo))
+
+	todo = FromString("Fifth todo")
+	todo.TodoID = 5
+	assert.NoError(t, storage.Restore(todo))
+	assert.Equal(t, "First todo\nSecond todo\nThird todo\n\nFifth todo\n", readTestFile(t, storage))
+}
+
+func TestFileStorageBatch(t *testing.T) {
+	storage := getTestFileStorage(t, "First todo\nSecond todo\n")
+
+	err := storage.Batch(func(tx Storage) error {
+		assert.NoError(t, tx.Delete(1))
+		return tx.Create(FromString("Third todo"))
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "\nSecond todo\nThird todo\n", readTestFile(t, storage))
+
+	// a failed batch leaves the file as it was
+	err = storage.Batch(func(tx Storage) error {
+		assert.NoError(t, tx.Delete(2))
+		return tx.Delete(1)
+	})
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, "\nSecond todo\nThird todo\n", readTestFile(t, storage))
+}
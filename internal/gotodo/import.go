@@ -0,0 +1,199 @@
+package gotodo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// importMaxLine is the longest line Import reads
+const importMaxLine = 1024 * 1024
+
+// ImportOptions configure an Import
+type ImportOptions func(im *importer)
+
+// importer holds the configuration of an Import
+type importer struct {
+	dryRun           bool
+	dedupe           bool
+	archiveCompleted bool
+}
+
+// ImportDryRun reports what an Import would do without saving anything
+func ImportDryRun(dryRun bool) ImportOptions {
+	return func(im *importer) {
+		im.dryRun = dryRun
+	}
+}
+
+// ImportDedupe skips lines matching a todo that is already stored or archived, or an earlier line
+// of the same import. Lines match when they are the same apart from whitespace.
+func ImportDedupe(dedupe bool) ImportOptions {
+	return func(im *importer) {
+		im.dedupe = dedupe
+	}
+}
+
+// ImportArchiveCompleted adds completed todos straight to the archive
+func ImportArchiveCompleted(archiveCompleted bool) ImportOptions {
+	return func(im *importer) {
+		im.archiveCompleted = archiveCompleted
+	}
+}
+
+// ImportResult is what an Import did with a line. TodoID is the ID the Todo was added under, in
+// the archive when Archived is set, and is 0 for a duplicate or a dry run.
+type ImportResult struct {
+	Line      int
+	TodoID    int
+	Todo      string
+	Archived  bool
+	Duplicate bool
+}
+
+// ImportWarning is a problem with a line that was still imported, such as a due date that can't be
+// parsed
+type ImportWarning struct {
+	Line    int
+	Message string
+}
+
+func (w ImportWarning) String() string {
+	return fmt.Sprintf("Line %d: %s", w.Line, w.Message)
+}
+
+// ImportReport lists what an Import did with every non-blank line, and the warnings for them
+type ImportReport struct {
+	Results  []ImportResult
+	Warnings []ImportWarning
+}
+
+// Added counts the lines that were added, or would be on a dry run, and how many of them were
+// archived
+func (me ImportReport) Added() (added int, archived int) {
+	for _, result := range me.Results {
+		if result.Duplicate {
+			continue
+		}
+		added++
+		if result.Archived {
+			archived++
+		}
+	}
+
+	return added, archived
+}
+
+// dedupeKey returns the text two todos share when they are duplicates
+func dedupeKey(todo *Todo) string {
+	return strings.Join(strings.Fields(todo.String()), " ")
+}
+
+// importLine is a non-blank line read by Import
+type importLine struct {
+	num  int
+	text string
+}
+
+// readImportLines reads the non-blank lines of a todo.txt file
+func readImportLines(r io.Reader) ([]importLine, error) {
+	lines := make([]importLine, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), importMaxLine)
+	for num := 1; scanner.Scan(); num++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		lines = append(lines, importLine{num: num, text: text})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Can't read the todos to import: %s", err)
+	}
+
+	return lines, nil
+}
+
+// Import adds every non-blank line of a todo.txt file as a Todo, in a single batch that can be
+// undone at once. Lines are added as written even when they have problems, which are reported as
+// warnings with their line numbers.
+func (tm *TodoManager) Import(r io.Reader, opts ...ImportOptions) (ImportReport, error) {
+	im := &importer{}
+	for _, opt := range opts {
+		opt(im)
+	}
+
+	report := ImportReport{Results: make([]ImportResult, 0), Warnings: make([]ImportWarning, 0)}
+
+	lines, err := readImportLines(r)
+	if err != nil {
+		return report, err
+	}
+
+	if im.archiveCompleted && tm.ArchiveStorage == nil {
+		return report, errNoArchive
+	}
+
+	run := func(op *operation) error {
+		seen := make(map[string]bool)
+		if im.dedupe {
+			for _, storage := range []Storage{op.storage, op.archived} {
+				if storage == nil {
+					continue
+				}
+				items, err := storage.List()
+				if err != nil {
+					return err
+				}
+				for _, todo := range items {
+					seen[dedupeKey(todo)] = true
+				}
+			}
+		}
+
+		for _, line := range lines {
+			todo := FromString(line.text)
+			result := ImportResult{Line: line.num, Todo: todo.String()}
+
+			key := dedupeKey(todo)
+			if im.dedupe && seen[key] {
+				result.Duplicate = true
+				report.Results = append(report.Results, result)
+				continue
+			}
+			seen[key] = true
+
+			if err := ValidateTodo(line.text); err != nil {
+				report.Warnings = append(report.Warnings, ImportWarning{Line: line.num, Message: err.Error()})
+			}
+
+			result.Archived = im.archiveCompleted && todo.Complete
+			if !im.dryRun {
+				var err error
+				if result.Archived {
+					err = op.createArchived(todo)
+				} else {
+					err = op.create(todo)
+				}
+				if err != nil {
+					return fmt.Errorf("Line %d: %s", line.num, err)
+				}
+				result.TodoID = todo.TodoID
+			}
+
+			report.Results = append(report.Results, result)
+		}
+
+		return nil
+	}
+
+	// A dry run only reads, so it doesn't need a batch
+	if im.dryRun {
+		return report, run(tm.begin("import"))
+	}
+
+	return report, tm.batch("import", run)
+}
@@ -0,0 +1,109 @@
+package gotodo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testImportFile = "(A) 2020-04-28 Call mom @phone\r\n" +
+	"\n" +
+	"x 2020-04-29 2020-04-28 Add parser test +gotodo\n" +
+	"Work on unit tests   +gotodo due:someday\n" +
+	"   \n" +
+	"(A) 2020-04-28 Call  mom @phone\n"
+
+func TestImport(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+
+	report, err := todoManager.Import(strings.NewReader(testImportFile))
+	assert.NoError(t, err)
+	assert.Equal(t, []ImportResult{
+		{Line: 1, TodoID: 3, Todo: "(A) 2020-04-28 Call mom @phone"},
+		{Line: 3, TodoID: 4, Todo: "x 2020-04-29 2020-04-28 Add parser test +gotodo"},
+		{Line: 4, TodoID: 5, Todo: "Work on unit tests   +gotodo due:someday"},
+		{Line: 6, TodoID: 6, Todo: "(A) 2020-04-28 Call  mom @phone"},
+	}, report.Results)
+	assert.Equal(t, []ImportWarning{{Line: 4, Message: "Invalid due date \"someday\", use YYYY-MM-DD or a day like tomorrow, fri or +3d"}}, report.Warnings)
+	assert.Equal(t, "Line 4: Invalid due date \"someday\", use YYYY-MM-DD or a day like tomorrow, fri or +3d", report.Warnings[0].String())
+
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(items))
+
+	// the whole import is undone at once
+	entries, err := todoManager.Undo(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "import", entries[0].Operation)
+	items, err = storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+}
+
+func TestImportDedupe(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+	existing := "Add  parser test +gotodo\n(A) 2020-04-28 Call mom @phone\n"
+
+	report, err := todoManager.Import(strings.NewReader(existing+testImportFile), ImportDedupe(true))
+	assert.NoError(t, err)
+
+	duplicates := make([]int, 0)
+	for _, result := range report.Results {
+		if result.Duplicate {
+			assert.Equal(t, 0, result.TodoID)
+			duplicates = append(duplicates, result.Line)
+		}
+	}
+	assert.Equal(t, []int{1, 3, 8}, duplicates)
+
+	added, archived := report.Added()
+	assert.Equal(t, 3, added)
+	assert.Equal(t, 0, archived)
+
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(items))
+}
+
+func TestImportArchiveCompleted(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+
+	report, err := todoManager.Import(strings.NewReader(testImportFile), ImportArchiveCompleted(true))
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResult{Line: 3, TodoID: 1, Todo: "x 2020-04-29 2020-04-28 Add parser test +gotodo", Archived: true}, report.Results[1])
+
+	added, archived := report.Added()
+	assert.Equal(t, 4, added)
+	assert.Equal(t, 1, archived)
+
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(items))
+
+	archivedItems, err := todoManager.ArchiveStorage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(archivedItems))
+
+	// an archived duplicate is found too
+	report, err = todoManager.Import(strings.NewReader(testImportFile), ImportDedupe(true), ImportArchiveCompleted(true))
+	assert.NoError(t, err)
+	added, _ = report.Added()
+	assert.Equal(t, 0, added)
+}
+
+func TestImportDryRun(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+	original := readTestFile(t, storage)
+
+	report, err := todoManager.Import(strings.NewReader(testImportFile), ImportDryRun(true), ImportDedupe(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(report.Results))
+	assert.Equal(t, 0, report.Results[0].TodoID)
+	assert.Equal(t, 1, len(report.Warnings))
+
+	assert.Equal(t, original, readTestFile(t, storage))
+	entries, _, err := todoManager.Journal.History()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+}
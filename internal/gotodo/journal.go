@@ -0,0 +1,254 @@
+package gotodo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// JournalChange is the state of a single Todo before and after an operation, as todo.txt strings.
+// An empty Before means the operation created the Todo and an empty After means it removed it.
+// Archive is set for changes to the archive rather than the main storage, and List for changes to
+// another list, such as when a Todo is moved to it.
+type JournalChange struct {
+	Archive bool   `json:"archive,omitempty"`
+	List    string `json:"list,omitempty"`
+	TodoID  int    `json:"id"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+}
+
+// JournalEntry is every change made by a single TodoManager operation
+type JournalEntry struct {
+	Time      time.Time       `json:"time"`
+	Operation string          `json:"operation"`
+	Changes   []JournalChange `json:"changes"`
+}
+
+// Journal keeps a history of operations in a JSON file so they can be undone and redone. Entries
+// before Position have been applied, while the ones after it were undone and can be redone.
+type Journal struct {
+	Path  string
+	Limit int
+}
+
+// journalState is the contents of a journal file
+type journalState struct {
+	Entries  []JournalEntry `json:"entries"`
+	Position int            `json:"position"`
+}
+
+// errNoRestore is returned when undoing a delete in storage that can't restore todos
+var errNoRestore = errors.New("Storage can't restore deleted todos")
+
+// load reads the journal file. A missing file is an empty journal.
+func (me *Journal) load() (*journalState, error) {
+	state := &journalState{Entries: make([]JournalEntry, 0)}
+
+	path, err := homedir.Expand(me.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(contents, state)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read journal %s: %s", me.Path, err)
+	}
+
+	return state, nil
+}
+
+// save writes the journal file
+func (me *Journal) save(state *journalState) error {
+	path, err := homedir.Expand(me.Path)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	// A journal cut short by a crash couldn't be read back, so never write it in place
+	return replaceFile(path, contents, 0600)
+}
+
+// Record adds an entry to the journal. Undone entries can no longer be redone once a new entry is
+// recorded, and the oldest entries are dropped when the journal grows past its limit.
+func (me *Journal) Record(entry JournalEntry) error {
+	state, err := me.load()
+	if err != nil {
+		return err
+	}
+
+	state.Entries = append(state.Entries[:state.Position], entry)
+	if me.Limit > 0 && len(state.Entries) > me.Limit {
+		state.Entries = state.Entries[len(state.Entries)-me.Limit:]
+	}
+	state.Position = len(state.Entries)
+
+	return me.save(state)
+}
+
+// History returns every entry in the journal, oldest first, and the number of entries that are
+// currently applied
+func (me *Journal) History() ([]JournalEntry, int, error) {
+	state, err := me.load()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return state.Entries, state.Position, nil
+}
+
+// Undo reverts the most recent operations in the journal, up to steps of them. It returns the
+// entries it reverted, most recent first.
+func (tm *TodoManager) Undo(steps int) ([]JournalEntry, error) {
+	return tm.travel(steps, true)
+}
+
+// Redo reapplies the most recently undone operations, up to steps of them. It returns the entries
+// it reapplied, oldest first.
+func (tm *TodoManager) Redo(steps int) ([]JournalEntry, error) {
+	return tm.travel(steps, false)
+}
+
+// travel moves backwards (undo) or forwards (redo) through the journal
+func (tm *TodoManager) travel(steps int, backwards bool) ([]JournalEntry, error) {
+	entries := make([]JournalEntry, 0)
+	if tm.Journal == nil {
+		return entries, errors.New("No journal is configured")
+	}
+
+	state, err := tm.Journal.load()
+	if err != nil {
+		return entries, err
+	}
+
+	for ; steps > 0; steps-- {
+		if backwards {
+			if state.Position == 0 {
+				break
+			}
+			entry := state.Entries[state.Position-1]
+			if err = tm.undoEntry(entry); err != nil {
+				break
+			}
+			state.Position--
+			entries = append(entries, entry)
+		} else {
+			if state.Position == len(state.Entries) {
+				break
+			}
+			entry := state.Entries[state.Position]
+			if err = tm.redoEntry(entry); err != nil {
+				break
+			}
+			state.Position++
+			entries = append(entries, entry)
+		}
+	}
+
+	if saveErr := tm.Journal.save(state); saveErr != nil {
+		return entries, saveErr
+	}
+
+	if err == nil && len(entries) == 0 {
+		if backwards {
+			err = errors.New("Nothing to undo")
+		} else {
+			err = errors.New("Nothing to redo")
+		}
+	}
+
+	return entries, err
+}
+
+// undoEntry reverts every change in a journal entry, last change first, in a single batch
+func (tm *TodoManager) undoEntry(entry JournalEntry) error {
+	return batchStorage(tm.Storage, tm.ArchiveStorage, func(storage Storage, archive Storage) error {
+		for i := len(entry.Changes) - 1; i >= 0; i-- {
+			change := entry.Changes[i]
+			err := revert(storage, archive, change, change.After, change.Before)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// redoEntry reapplies every change in a journal entry, first change first, in a single batch
+func (tm *TodoManager) redoEntry(entry JournalEntry) error {
+	return batchStorage(tm.Storage, tm.ArchiveStorage, func(storage Storage, archive Storage) error {
+		for _, change := range entry.Changes {
+			err := revert(storage, archive, change, change.Before, change.After)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// revert moves the Todo of a change from one state to another. The Todo must still be in the from
+// state, so changes made outside of gotodo are never overwritten.
+func revert(storage Storage, archive Storage, change JournalChange, from string, to string) error {
+	todoID := change.TodoID
+	if change.Archive {
+		if archive == nil {
+			return errNoArchive
+		}
+		storage = archive
+	} else if change.List != "" {
+		var err error
+		if storage, err = listStorage(storage, change.List); err != nil {
+			return err
+		}
+	}
+
+	current, err := storage.Get(todoID)
+	if from == "" {
+		if err == nil {
+			return fmt.Errorf("Todo ID %d already exists", todoID)
+		} else if err != ErrNotFound {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else if current.String() != FromString(from).String() {
+		return fmt.Errorf("Todo ID %d was changed elsewhere, leaving it as is", todoID)
+	}
+
+	if to == "" {
+		return storage.Delete(todoID)
+	}
+
+	todo := FromString(to)
+	todo.TodoID = todoID
+
+	if from == "" {
+		restorer, ok := storage.(Restorer)
+		if !ok {
+			return errNoRestore
+		}
+		return restorer.Restore(todo)
+	}
+
+	return storage.Update(todoID, todo)
+}
@@ -0,0 +1,208 @@
+package gotodo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// journalTodos are the todos the journal tests start with
+var journalTodos = []string{
+	"(B) 2020-04-28 Work on unit tests @codehealth +gotodo",
+	"Add parser test +gotodo",
+}
+
+func TestJournalRecord(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, journalTodos, withTestJournal(0))
+
+	assert.NoError(t, todoManager.Prioritize(2, "A"))
+	_, err := todoManager.Add("Add journal test")
+	assert.NoError(t, err)
+	assert.NoError(t, todoManager.Delete(1))
+
+	entries, position, err := todoManager.Journal.History()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, 3, position)
+
+	assert.Equal(t, "prioritize", entries[0].Operation)
+	assert.Equal(t, []JournalChange{{TodoID: 2, Before: "Add parser test +gotodo", After: "(A) Add parser test +gotodo"}}, entries[0].Changes)
+	assert.Equal(t, "add", entries[1].Operation)
+	assert.Equal(t, []JournalChange{{TodoID: 3, After: "Add journal test"}}, entries[1].Changes)
+	assert.Equal(t, "remove", entries[2].Operation)
+	assert.Equal(t, []JournalChange{{TodoID: 1, Before: "(B) 2020-04-28 Work on unit tests @codehealth +gotodo"}}, entries[2].Changes)
+}
+
+func TestJournalUndoRedo(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+	original := readTestFile(t, storage)
+
+	assert.NoError(t, todoManager.Prioritize(2, "A"))
+	_, err := todoManager.Add("Add journal test")
+	assert.NoError(t, err)
+	assert.NoError(t, todoManager.Delete(1))
+	changed := readTestFile(t, storage)
+	assert.Equal(t, "\n(A) Add parser test +gotodo\nAdd journal test\n", changed)
+
+	entries, err := todoManager.Undo(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "remove", entries[0].Operation)
+
+	todo, err := todoManager.Storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Work on unit tests @codehealth +gotodo", todo.Description)
+
+	entries, err = todoManager.Undo(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "add", entries[0].Operation)
+	assert.Equal(t, "prioritize", entries[1].Operation)
+	assert.Equal(t, original+"\n\n", readTestFile(t, storage))
+
+	_, err = todoManager.Undo(1)
+	assert.EqualError(t, err, "Nothing to undo")
+
+	entries, err = todoManager.Redo(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, changed, readTestFile(t, storage))
+
+	_, err = todoManager.Redo(1)
+	assert.EqualError(t, err, "Nothing to redo")
+}
+
+func TestJournalRecordDropsRedo(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, journalTodos, withTestJournal(0))
+
+	assert.NoError(t, todoManager.Prioritize(2, "A"))
+	assert.NoError(t, todoManager.Prioritize(2, "B"))
+	_, err := todoManager.Undo(1)
+	assert.NoError(t, err)
+	assert.NoError(t, todoManager.Prioritize(2, "C"))
+
+	entries, position, err := todoManager.Journal.History()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, 2, position)
+
+	_, err = todoManager.Redo(1)
+	assert.EqualError(t, err, "Nothing to redo")
+}
+
+func TestJournalLimit(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, journalTodos, withTestJournal(2))
+
+	assert.NoError(t, todoManager.Prioritize(2, "A"))
+	assert.NoError(t, todoManager.Prioritize(2, "B"))
+	assert.NoError(t, todoManager.Prioritize(2, "C"))
+
+	entries, position, err := todoManager.Journal.History()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, 2, position)
+	assert.Equal(t, "(B) Add parser test +gotodo", entries[1].Changes[0].Before)
+}
+
+func TestJournalUndoConflict(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+
+	assert.NoError(t, todoManager.Prioritize(2, "A"))
+
+	// Change the todo behind the journal's back
+	assert.NoError(t, storage.Update(2, FromString("(C) Add parser test +gotodo")))
+
+	_, err := todoManager.Undo(1)
+	assert.EqualError(t, err, "Todo ID 2 was changed elsewhere, leaving it as is")
+
+	todo, err := todoManager.Storage.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, todo.Priority)
+}
+
+func TestJournalUndoArchive(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+	archive := getTestFileStorage(t, "")
+	todoManager.ArchiveStorage = archive
+	todoManager.AutoArchive = true
+	original := readTestFile(t, storage)
+
+	assert.NoError(t, todoManager.Complete(1))
+	_, err := todoManager.Storage.Get(1)
+	assert.Equal(t, ErrNotFound, err)
+
+	entries, err := todoManager.Undo(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "complete", entries[0].Operation)
+	assert.Equal(t, original+"\n", readTestFile(t, storage))
+	assert.Equal(t, "\n", readTestFile(t, archive))
+}
+
+func TestJournalSaveReplacesFile(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+	dir := filepath.Dir(storage.Path)
+	assert.NoError(t, ioutil.WriteFile(todoManager.Journal.Path, []byte(`{"entries":[],"position":0}`), 0640))
+
+	assert.NoError(t, todoManager.Prioritize(2, "A"))
+
+	// The journal is renamed into place, keeping its permissions and leaving no temp file behind
+	info, err := os.Stat(todoManager.Journal.Path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode())
+
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	names := make([]string, 0)
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	assert.Equal(t, []string{"done.txt", "journal.json", "todo.txt"}, names)
+}
+
+func TestJournalDisabled(t *testing.T) {
+	todoManager := getTestTodoManager()
+
+	_, err := todoManager.Undo(1)
+	assert.Error(t, err)
+}
+
+func TestPatch(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+	assert.NoError(t, storage.Create(FromString("Water plants rec:bogus")))
+
+	text := "Add query test +gotodo"
+	priority := "B"
+	complete := true
+	assert.NoError(t, todoManager.Patch(2, TodoPatch{Text: &text, Priority: &priority}))
+
+	todo, err := storage.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "(B) Add query test +gotodo", todo.String())
+
+	// every field of a patch is a single change in the journal
+	entries, _, err := todoManager.Journal.History()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "patch", entries[0].Operation)
+
+	// when a later field fails, the earlier ones aren't saved either
+	priority = "A"
+	assert.Error(t, todoManager.Patch(3, TodoPatch{Priority: &priority, Complete: &complete}))
+	todo, err = storage.Get(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Water plants rec:bogus", todo.String())
+
+	text = "Add query test due:someday"
+	assert.EqualError(t, todoManager.Patch(2, TodoPatch{Text: &text}), "Invalid due date \"someday\", use YYYY-MM-DD or a day like tomorrow, fri or +3d")
+	priority = "1"
+	assert.EqualError(t, todoManager.Patch(2, TodoPatch{Priority: &priority}), "Invalid priority value: 1")
+
+	_, err = todoManager.Undo(1)
+	assert.NoError(t, err)
+	todo, err = storage.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Add parser test +gotodo", todo.String())
+}
@@ -0,0 +1,353 @@
+package gotodo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Attributes linking todos. parent:3 makes a todo a subtask of todo 3, dep:3 makes it wait for
+// todo 3 to be completed, and blocks:3 makes todo 3 wait for it. dep and blocks take a comma
+// separated list of IDs, like dep:3,4.
+const (
+	ParentAttribute  = "parent"
+	DependsAttribute = "dep"
+	BlocksAttribute  = "blocks"
+)
+
+// CycleError is returned when a change would link a todo to itself through its parents or
+// dependencies. Path lists the todo IDs around the cycle, starting and ending with the same ID.
+type CycleError struct {
+	Kind string
+	Path []int
+}
+
+func (e *CycleError) Error() string {
+	path := make([]string, len(e.Path))
+	for i, todoID := range e.Path {
+		path[i] = strconv.Itoa(todoID)
+	}
+
+	return fmt.Sprintf("Todo ID %d can't be linked, it would form a %s cycle: %s", e.Path[0], e.Kind, strings.Join(path, " -> "))
+}
+
+// ParentID returns the ID of the parent of a Todo, or 0 when it has none
+func (t *Todo) ParentID() int {
+	ids := parseLinkIDs(t.Attributes[ParentAttribute])
+	if len(ids) != 1 {
+		return 0
+	}
+
+	return ids[0]
+}
+
+// DependsOn returns the IDs of the todos a Todo waits for with dep:
+func (t *Todo) DependsOn() []int {
+	return parseLinkIDs(t.Attributes[DependsAttribute])
+}
+
+// Blocks returns the IDs of the todos that wait for a Todo with blocks:
+func (t *Todo) Blocks() []int {
+	return parseLinkIDs(t.Attributes[BlocksAttribute])
+}
+
+// hasLinks checks whether a Todo has any link attributes
+func (t *Todo) hasLinks() bool {
+	return t.hasAttribute(ParentAttribute) || t.hasAttribute(DependsAttribute) || t.hasAttribute(BlocksAttribute)
+}
+
+// linksTo checks whether a Todo links to todoID with parent:, dep: or blocks:
+func (t *Todo) linksTo(todoID int) bool {
+	if t.ParentID() == todoID {
+		return true
+	}
+	for _, ids := range [][]int{t.DependsOn(), t.Blocks()} {
+		for _, id := range ids {
+			if id == todoID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// unlink removes todoID from the parent:, dep: and blocks: links of a Todo, dropping a link left
+// without IDs. It returns whether the Todo changed.
+func (t *Todo) unlink(todoID int) bool {
+	target := strconv.Itoa(todoID)
+	description := rewriteWords(t.Description, func(word string) string {
+		key := attributeKey(word)
+		if key != ParentAttribute && key != DependsAttribute && key != BlocksAttribute {
+			return word
+		}
+
+		parts := make([]string, 0)
+		for _, part := range strings.Split(word[len(key)+1:], ",") {
+			if part != target {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) == 0 {
+			return ""
+		}
+
+		return key + ":" + strings.Join(parts, ",")
+	})
+
+	// A todo that is nothing but links keeps them rather than lose its description
+	if description == t.Description || description == "" {
+		return false
+	}
+
+	t.setDescription(description)
+	return true
+}
+
+// parseLinkIDs parses a comma separated list of todo IDs, skipping anything that isn't one
+func parseLinkIDs(value string) []int {
+	ids := make([]int, 0)
+	if value == "" {
+		return ids
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		todoID, err := strconv.Atoi(part)
+		if err != nil || todoID < 1 {
+			continue
+		}
+		ids = append(ids, todoID)
+	}
+
+	return ids
+}
+
+// TodoLinks indexes the parent and dependency links between a list of todos. Links to todos that
+// aren't in the list, such as archived ones, are ignored.
+type TodoLinks struct {
+	todos        map[int]*Todo
+	children     map[int][]int
+	dependencies map[int][]int
+}
+
+// NewTodoLinks indexes the links between items
+func NewTodoLinks(items TodoList) *TodoLinks {
+	links := &TodoLinks{
+		todos:        make(map[int]*Todo),
+		children:     make(map[int][]int),
+		dependencies: make(map[int][]int),
+	}
+
+	for _, todo := range items {
+		links.todos[todo.TodoID] = todo
+	}
+
+	for _, todo := range items {
+		if parentID := todo.ParentID(); links.has(parentID) {
+			links.children[parentID] = append(links.children[parentID], todo.TodoID)
+		}
+
+		for _, depID := range todo.DependsOn() {
+			if links.has(depID) {
+				links.dependencies[todo.TodoID] = append(links.dependencies[todo.TodoID], depID)
+			}
+		}
+
+		// blocks: is the same link as dep:, written on the other todo
+		for _, blockedID := range todo.Blocks() {
+			if links.has(blockedID) {
+				links.dependencies[blockedID] = append(links.dependencies[blockedID], todo.TodoID)
+			}
+		}
+	}
+
+	for todoID := range links.dependencies {
+		links.dependencies[todoID] = uniqueIDs(links.dependencies[todoID])
+	}
+
+	return links
+}
+
+// has checks whether a todo ID is in the list
+func (me *TodoLinks) has(todoID int) bool {
+	_, ok := me.todos[todoID]
+	return ok
+}
+
+// Children returns the IDs of the subtasks of a todo
+func (me *TodoLinks) Children(todoID int) []int {
+	return me.children[todoID]
+}
+
+// Descendants returns the IDs of the subtasks of a todo, their subtasks and so on
+func (me *TodoLinks) Descendants(todoID int) []int {
+	descendants := make([]int, 0)
+	seen := map[int]void{todoID: {}}
+
+	queue := append([]int{}, me.children[todoID]...)
+	for len(queue) > 0 {
+		childID := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[childID]; ok {
+			continue
+		}
+		seen[childID] = void{}
+
+		descendants = append(descendants, childID)
+		queue = append(queue, me.children[childID]...)
+	}
+
+	return descendants
+}
+
+// Dependencies returns the IDs of the todos a todo waits for, from its dep: and their blocks:
+func (me *TodoLinks) Dependencies(todoID int) []int {
+	return me.dependencies[todoID]
+}
+
+// BlockedBy returns the IDs of the pending todos a pending todo waits for. A todo is blocked
+// until all of them are completed.
+func (me *TodoLinks) BlockedBy(todoID int) []int {
+	blockers := make([]int, 0)
+
+	todo, ok := me.todos[todoID]
+	if !ok || todo.Complete {
+		return blockers
+	}
+
+	for _, depID := range me.dependencies[todoID] {
+		if !me.todos[depID].Complete {
+			blockers = append(blockers, depID)
+		}
+	}
+
+	return blockers
+}
+
+// checkCycle returns a *CycleError if a todo is its own ancestor or depends on itself
+func (me *TodoLinks) checkCycle(todoID int) error {
+	// Follow the parents up from the todo
+	path := []int{todoID}
+	seen := map[int]void{todoID: {}}
+	for current := me.todos[todoID]; current != nil; {
+		parentID := current.ParentID()
+		if parentID == todoID {
+			return &CycleError{Kind: "parent", Path: append(path, todoID)}
+		}
+		if _, ok := seen[parentID]; ok || !me.has(parentID) {
+			break
+		}
+		seen[parentID] = void{}
+		path = append(path, parentID)
+		current = me.todos[parentID]
+	}
+
+	// Search the dependencies for a way back to the todo
+	if path := me.findDependency(todoID, todoID, make(map[int]void)); path != nil {
+		return &CycleError{Kind: "dependency", Path: append([]int{todoID}, path...)}
+	}
+
+	return nil
+}
+
+// findDependency returns the path of dependencies from fromID to targetID, or nil if there is none
+func (me *TodoLinks) findDependency(fromID int, targetID int, seen map[int]void) []int {
+	for _, depID := range me.dependencies[fromID] {
+		if depID == targetID {
+			return []int{depID}
+		}
+		if _, ok := seen[depID]; ok {
+			continue
+		}
+		seen[depID] = void{}
+
+		if path := me.findDependency(depID, targetID, seen); path != nil {
+			return append([]int{depID}, path...)
+		}
+	}
+
+	return nil
+}
+
+// Links indexes the links between the todos in storage
+func (tm *TodoManager) Links() (*TodoLinks, error) {
+	items, err := tm.Storage.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTodoLinks(items), nil
+}
+
+// uniqueIDs sorts todo IDs and removes repeats
+func uniqueIDs(ids []int) []int {
+	sort.Ints(ids)
+
+	unique := ids[:0]
+	for i, todoID := range ids {
+		if i == 0 || todoID != ids[i-1] {
+			unique = append(unique, todoID)
+		}
+	}
+
+	return unique
+}
+
+// TreeItem is a Todo placed in a tree of subtasks. Depth is 0 for todos without a parent in the
+// tree, and Last is set for the last subtask of each parent.
+type TreeItem struct {
+	Todo  *Todo
+	Depth int
+	Last  bool
+}
+
+// Tree arranges items into a tree of subtasks, keeping the order of items among siblings. A todo
+// whose parent isn't in items is placed at the top of the tree.
+func Tree(items TodoList) []TreeItem {
+	links := NewTodoLinks(items)
+	tree := make([]TreeItem, 0, len(items))
+	placed := make(map[int]void)
+
+	// Children in the order they appear in items
+	position := make(map[int]int)
+	for i, todo := range items {
+		position[todo.TodoID] = i
+	}
+
+	var place func(todo *Todo, depth int, last bool)
+	place = func(todo *Todo, depth int, last bool) {
+		placed[todo.TodoID] = void{}
+		tree = append(tree, TreeItem{Todo: todo, Depth: depth, Last: last})
+
+		children := make([]int, 0)
+		for _, childID := range links.Children(todo.TodoID) {
+			if _, ok := placed[childID]; !ok {
+				children = append(children, childID)
+			}
+		}
+		sort.Slice(children, func(i, j int) bool {
+			return position[children[i]] < position[children[j]]
+		})
+
+		for i, childID := range children {
+			place(links.todos[childID], depth+1, i == len(children)-1)
+		}
+	}
+
+	for _, todo := range items {
+		if _, ok := placed[todo.TodoID]; ok || links.has(todo.ParentID()) {
+			continue
+		}
+		place(todo, 0, false)
+	}
+
+	// Todos caught in a cycle of parents have no root, so place them at the top as well
+	for _, todo := range items {
+		if _, ok := placed[todo.TodoID]; !ok {
+			place(todo, 0, false)
+		}
+	}
+
+	return tree
+}
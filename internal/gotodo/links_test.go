@@ -0,0 +1,200 @@
+package gotodo
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// linksPlan is a small project plan:
+//
+//	1 Launch website
+//	├─ 2 Design pages
+//	│  ├─ 3 Write copy
+//	│  └─ 4 Review copy, after 3
+//	└─ 5 Build pages, after 2 and 6
+//	6 Buy domain
+var linksPlan = []string{
+	"(A) Launch website +web",
+	"Design pages parent:1",
+	"Write copy parent:2",
+	"Review copy parent:2 dep:3",
+	"Build pages parent:1 dep:2",
+	"Buy domain blocks:5",
+}
+
+func TestLinkAttributes(t *testing.T) {
+	todo := FromString("Build pages parent:1 dep:2,x,3 blocks:0")
+	assert.Equal(t, 1, todo.ParentID())
+	assert.Equal(t, []int{2, 3}, todo.DependsOn())
+	assert.Equal(t, []int{}, todo.Blocks())
+
+	todo = FromString("Build pages parent:1,2")
+	assert.Equal(t, 0, todo.ParentID())
+}
+
+func TestTodoLinks(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, linksPlan)
+
+	links, err := todoManager.Links()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 5}, links.Children(1))
+	assert.Equal(t, []int{2, 5, 3, 4}, links.Descendants(1))
+	assert.Equal(t, []int{2, 6}, links.Dependencies(5))
+	assert.Equal(t, []int{3}, links.BlockedBy(4))
+	assert.Equal(t, []int{}, links.BlockedBy(3))
+
+	assert.NoError(t, todoManager.Complete(3))
+	links, err = todoManager.Links()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{}, links.BlockedBy(4))
+}
+
+func TestListBlocked(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, linksPlan)
+
+	items, err := todoManager.List(TodoListFilter{})
+	assert.NoError(t, err)
+	ids := make([]int, len(items))
+	for i, todo := range items {
+		ids[i] = todo.TodoID
+	}
+	assert.Equal(t, []int{1, 2, 3, 6}, ids)
+
+	items, err = todoManager.List(TodoListFilter{ShowBlocked: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(items))
+}
+
+func TestLinkCycles(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, linksPlan)
+	original := readTestFile(t, storage)
+
+	err := todoManager.Update(1, "(A) Launch website +web parent:3")
+	assert.EqualError(t, err, "Todo ID 1 can't be linked, it would form a parent cycle: 1 -> 3 -> 2 -> 1")
+	assert.IsType(t, &CycleError{}, err)
+
+	err = todoManager.Update(3, "Write copy parent:2 dep:4")
+	assert.EqualError(t, err, "Todo ID 3 can't be linked, it would form a dependency cycle: 3 -> 4 -> 3")
+
+	// blocks: links the other way around
+	err = todoManager.Update(5, "Build pages parent:1 dep:2 blocks:6")
+	assert.EqualError(t, err, "Todo ID 5 can't be linked, it would form a dependency cycle: 5 -> 6 -> 5")
+
+	_, err = todoManager.Add("Wait for myself dep:7")
+	assert.EqualError(t, err, "Todo ID 7 can't be linked, it would form a dependency cycle: 7 -> 7")
+
+	// nothing is saved when a change forms a cycle
+	assert.Equal(t, original, readTestFile(t, storage))
+
+	assert.NoError(t, todoManager.Update(6, "Buy domain blocks:5 parent:1"))
+}
+
+func TestCompleteCascade(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, linksPlan)
+	assert.NoError(t, todoManager.Complete(3))
+
+	results, err := todoManager.CompleteCascade([]int{2, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []BulkResult{{TodoID: 2}, {TodoID: 4}}, results)
+
+	items, err := storage.List()
+	assert.NoError(t, err)
+	complete := make([]bool, len(items))
+	for i, todo := range items {
+		complete[i] = todo.Complete
+	}
+	assert.Equal(t, []bool{false, true, true, true, false, false}, complete)
+}
+
+func TestArchiveUnlinks(t *testing.T) {
+	plan := strings.Join([]string{
+		"Launch website",
+		"Write copy parent:1",
+		"Review copy parent:1 dep:2,4",
+		"Proofread copy",
+		"Publish dep:2",
+	}, "\n")
+	storage := getTestFileStorage(t, plan)
+	archive := getTestFileStorage(t, "")
+	todoManager := NewTodoManager(
+		WithFileStorage(storage.Path),
+		WithFileArchive(archive.Path),
+		WithAutoArchive(true),
+		WithJournal(filepath.Join(filepath.Dir(storage.Path), "journal.json"), 0),
+	)
+
+	// Archiving a todo drops it from the links of the todos left behind
+	assert.NoError(t, todoManager.Complete(2))
+	todo, err := todoManager.Storage.Get(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Review copy parent:1 dep:4", todo.Description)
+	todo, err = todoManager.Storage.Get(5)
+	assert.NoError(t, err)
+	assert.Equal(t, "Publish", todo.Description)
+
+	// Undo brings the links back along with the todo
+	_, err = todoManager.Undo(1)
+	assert.NoError(t, err)
+	assert.Equal(t, plan+"\n", readTestFile(t, storage))
+
+	// Archiving completed todos later drops their links too
+	todoManager = NewTodoManager(WithFileStorage(storage.Path), WithFileArchive(archive.Path))
+	assert.NoError(t, todoManager.Complete(4))
+	archived, err := todoManager.Archive()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	todo, err = todoManager.Storage.Get(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Review copy parent:1 dep:2", todo.Description)
+}
+
+func TestDeleteUnlinks(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, linksPlan, withTestJournal(0))
+
+	// Removing todos drops them from every link in one pass, like archiving them
+	results, err := todoManager.DeleteAll([]int{3, 6})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results))
+
+	todo, err := todoManager.Storage.Get(4)
+	assert.NoError(t, err)
+	assert.Equal(t, "Review copy parent:2", todo.Description)
+	todo, err = todoManager.Storage.Get(5)
+	assert.NoError(t, err)
+	assert.Equal(t, "Build pages parent:1 dep:2", todo.Description)
+
+	entries, err := todoManager.Undo(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	todo, err = todoManager.Storage.Get(4)
+	assert.NoError(t, err)
+	assert.Equal(t, "Review copy parent:2 dep:3", todo.Description)
+}
+
+func TestTree(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, linksPlan)
+
+	items, err := todoManager.List(TodoListFilter{ShowBlocked: true})
+	assert.NoError(t, err)
+
+	tree := Tree(items)
+	ids := make([]int, len(tree))
+	depths := make([]int, len(tree))
+	for i, item := range tree {
+		ids[i] = item.Todo.TodoID
+		depths[i] = item.Depth
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, ids)
+	assert.Equal(t, []int{0, 1, 2, 2, 1, 0}, depths)
+	assert.Equal(t, false, tree[1].Last)
+	assert.Equal(t, true, tree[3].Last)
+	assert.Equal(t, true, tree[4].Last)
+
+	// a subtask whose parent is filtered out moves to the top
+	tree = Tree(items[2:])
+	assert.Equal(t, 3, tree[0].Todo.TodoID)
+	assert.Equal(t, 0, tree[0].Depth)
+}
@@ -0,0 +1,377 @@
+package gotodo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+// ListInfo counts the todos in a list. A list is a Bolt bucket, and its archive is the bucket of
+// the same name with an "Archive" suffix.
+type ListInfo struct {
+	Name     string
+	Pending  int
+	Done     int
+	Archived int
+	Current  bool
+}
+
+// errNoLists is returned when managing lists without Bolt storage
+var errNoLists = errors.New("Lists are only supported with bolt storage")
+
+// listStorage returns the Storage of the list called name within the Bolt transaction of storage
+func listStorage(storage Storage, name string) (Storage, error) {
+	tx, ok := storage.(*boltTx)
+	if !ok {
+		return nil, errNoLists
+	}
+
+	if tx.tx.Bucket([]byte(name)) == nil {
+		return nil, fmt.Errorf("List \"%s\" does not exist", name)
+	}
+
+	return openBoltTx(tx.tx, []byte(name))
+}
+
+// boltStorage returns the BoltStorage of a TodoManager, or errNoLists
+func (tm *TodoManager) boltStorage() (*BoltStorage, error) {
+	storage, ok := tm.Storage.(*BoltStorage)
+	if !ok {
+		return nil, errNoLists
+	}
+
+	return storage, nil
+}
+
+// archiveName returns the name of the archive bucket of a list. The current list's archive may be
+// configured with another name.
+func (tm *TodoManager) archiveName(list string) string {
+	if storage, ok := tm.Storage.(*BoltStorage); ok && string(storage.Bucket) == list {
+		if archive, ok := tm.ArchiveStorage.(*BoltStorage); ok {
+			return string(archive.Bucket)
+		}
+	}
+
+	return list + archiveBucketSuffix
+}
+
+// isArchive checks whether a bucket name is the archive of a list rather than a list
+func (tm *TodoManager) isArchive(name string) bool {
+	if strings.HasSuffix(name, archiveBucketSuffix) {
+		return true
+	}
+
+	storage, ok := tm.Storage.(*BoltStorage)
+	return ok && name != string(storage.Bucket) && name == tm.archiveName(string(storage.Bucket))
+}
+
+// checkListName returns an error if name can't be used for a list
+func checkListName(name string) error {
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, "/\\") {
+		return fmt.Errorf("Invalid list name \"%s\"", name)
+	} else if strings.HasSuffix(name, archiveBucketSuffix) {
+		return fmt.Errorf("Invalid list name \"%s\", it can't end with \"%s\"", name, archiveBucketSuffix)
+	}
+
+	return nil
+}
+
+// countBucket counts the pending and completed todos in a bucket
+func countBucket(b *bolt.Bucket) (pending int, done int, err error) {
+	err = b.ForEach(func(k, v []byte) error {
+		todoID := boltTodoID(k)
+		if todoID == 0 {
+			return nil
+		}
+
+		todo, err := decodeBoltRecord(v)
+		if err != nil {
+			return fmt.Errorf("Can't read Todo ID %d: %s", todoID, err)
+		}
+		if todo.Complete {
+			done++
+		} else {
+			pending++
+		}
+
+		return nil
+	})
+
+	return pending, done, err
+}
+
+// Lists returns every list with the number of todos in it, in alphabetical order. The archive of
+// a list is counted with it rather than listed on its own, and so is any bucket ending in
+// "Archive".
+func (tm *TodoManager) Lists() ([]ListInfo, error) {
+	storage, err := tm.boltStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := storage.Buckets()
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]ListInfo, 0)
+	err = storage.viewDB(func(tx *bolt.Tx) error {
+		for _, name := range names {
+			if tm.isArchive(name) {
+				continue
+			}
+
+			pending, done, err := countBucket(tx.Bucket([]byte(name)))
+			if err != nil {
+				return err
+			}
+			info := ListInfo{Name: name, Pending: pending, Done: done, Current: name == string(storage.Bucket)}
+
+			if archive := tx.Bucket([]byte(tm.archiveName(name))); archive != nil {
+				pending, done, err := countBucket(archive)
+				if err != nil {
+					return err
+				}
+				info.Archived = pending + done
+			}
+
+			lists = append(lists, info)
+		}
+
+		return nil
+	})
+
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].Name < lists[j].Name
+	})
+
+	return lists, err
+}
+
+// CreateList creates an empty list
+func (tm *TodoManager) CreateList(name string) error {
+	storage, err := tm.boltStorage()
+	if err != nil {
+		return err
+	}
+	if err := checkListName(name); err != nil {
+		return err
+	}
+
+	return storage.updateDB(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(name)) != nil {
+			return fmt.Errorf("List \"%s\" already exists", name)
+		}
+
+		_, err := openBoltTx(tx, []byte(name))
+		return err
+	})
+}
+
+// copyBucket copies every key of a bucket, along with its sequence, to a new bucket
+func copyBucket(tx *bolt.Tx, from string, to string) error {
+	src := tx.Bucket([]byte(from))
+	if src == nil {
+		return nil
+	}
+
+	dst, err := tx.CreateBucket([]byte(to))
+	if err != nil {
+		return err
+	}
+
+	err = src.ForEach(func(k, v []byte) error {
+		return dst.Put(k, v)
+	})
+	if err != nil {
+		return err
+	}
+
+	return dst.SetSequence(src.Sequence())
+}
+
+// copyList copies a list and its archive to a new list, and removes the original if remove is set
+func (tm *TodoManager) copyList(from string, to string, remove bool) error {
+	storage, err := tm.boltStorage()
+	if err != nil {
+		return err
+	}
+	if err := checkListName(to); err != nil {
+		return err
+	}
+
+	return storage.updateDB(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(from)) == nil || tm.isArchive(from) {
+			return fmt.Errorf("List \"%s\" does not exist", from)
+		}
+
+		pairs := [][2]string{{from, to}, {tm.archiveName(from), to + archiveBucketSuffix}}
+		for _, pair := range pairs {
+			if tx.Bucket([]byte(pair[1])) != nil {
+				return fmt.Errorf("List \"%s\" already exists", to)
+			}
+		}
+
+		for _, pair := range pairs {
+			if err := copyBucket(tx, pair[0], pair[1]); err != nil {
+				return err
+			}
+			if remove && tx.Bucket([]byte(pair[0])) != nil {
+				if err := tx.DeleteBucket([]byte(pair[0])); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// RenameList renames a list and its archive. The todos keep their IDs.
+func (tm *TodoManager) RenameList(oldName string, newName string) error {
+	return tm.copyList(oldName, newName, true)
+}
+
+// CloneList copies a list and its archive to a new list. The todos keep their IDs.
+func (tm *TodoManager) CloneList(from string, to string) error {
+	return tm.copyList(from, to, false)
+}
+
+// DeleteList deletes a list and its archive. A list with todos in it or its archive is only
+// deleted with force.
+func (tm *TodoManager) DeleteList(name string, force bool) error {
+	storage, err := tm.boltStorage()
+	if err != nil {
+		return err
+	}
+
+	if name == string(storage.Bucket) {
+		return fmt.Errorf("Can't delete list \"%s\" while using it", name)
+	}
+
+	return storage.updateDB(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(name))
+		if b == nil || tm.isArchive(name) {
+			return fmt.Errorf("List \"%s\" does not exist", name)
+		}
+
+		buckets := []*bolt.Bucket{b}
+		if archive := tx.Bucket([]byte(tm.archiveName(name))); archive != nil {
+			buckets = append(buckets, archive)
+		}
+
+		count := 0
+		for _, b := range buckets {
+			pending, done, err := countBucket(b)
+			if err != nil {
+				return err
+			}
+			count += pending + done
+		}
+		if count > 0 && !force {
+			return fmt.Errorf("List \"%s\" has %d todos, delete it with --force to remove them", name, count)
+		}
+
+		for _, bucket := range []string{name, tm.archiveName(name)} {
+			if tx.Bucket([]byte(bucket)) == nil {
+				continue
+			}
+			if err := tx.DeleteBucket([]byte(bucket)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// MoveOptions configure a Move
+type MoveOptions func(mv *mover)
+
+// mover holds the configuration of a Move
+type mover struct {
+	timeLog *TimeLog
+}
+
+// MoveTimeLog moves the work sessions of the todo to timeLog, the time log of the list it moves
+// to, under its new ID and the name of that list. timeLog may be the TodoManager's own time log
+// when lists share one.
+func MoveTimeLog(timeLog *TimeLog) MoveOptions {
+	return func(mv *mover) {
+		mv.timeLog = timeLog
+	}
+}
+
+// Move moves a Todo to another list, where it gets a new ID, and returns the new ID. The todo is
+// saved exactly as it was, and the move can be undone in the list it came from, although its work
+// sessions stay in the other list. A todo linked to others with parent:, dep: or blocks: can't be
+// moved, as the links would point at todos of the other list.
+func (tm *TodoManager) Move(todoID int, list string, opts ...MoveOptions) (int, error) {
+	mv := &mover{}
+	for _, opt := range opts {
+		opt(mv)
+	}
+
+	storage, err := tm.boltStorage()
+	if err != nil {
+		return 0, err
+	}
+	if list == string(storage.Bucket) {
+		return 0, fmt.Errorf("Todo ID %d is already in list \"%s\"", todoID, list)
+	}
+
+	if tm.isArchive(list) {
+		return 0, fmt.Errorf("List \"%s\" does not exist", list)
+	}
+
+	newID := 0
+	err = tm.batch("move", func(op *operation) error {
+		target, err := op.listStorage(list)
+		if err != nil {
+			return err
+		}
+
+		todo, err := op.get(todoID)
+		if err != nil {
+			return err
+		}
+
+		items, err := op.list()
+		if err != nil {
+			return err
+		}
+		linked := todo.hasLinks()
+		for _, item := range items {
+			linked = linked || item.linksTo(todoID)
+		}
+		if linked {
+			return fmt.Errorf("Todo ID %d is linked to other todos, remove its parent:, dep: and blocks: links first", todoID)
+		}
+
+		if err := op.delete(todoID); err != nil {
+			return err
+		}
+
+		if err := op.createIn(list, target, todo); err != nil {
+			return err
+		}
+		newID = todo.TodoID
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// The sessions are recorded under the list they move to, whatever list timeLog was opened for
+	if mv.timeLog == nil {
+		return newID, nil
+	}
+	timeLog := *mv.timeLog
+	timeLog.List = list
+
+	return newID, tm.moveSessions(todoID, newID, &timeLog)
+}
@@ -0,0 +1,188 @@
+package gotodo
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// listsTodos are the todos of the Bolt list called Work the list tests start with
+var listsTodos = []string{"(A) Write report +client", "Call client @phone", "x 2020-04-29 Send invoice"}
+
+func TestLists(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, listsTodos, withTestBolt(t, "Work"), withTestJournal(0))
+	_, err := todoManager.Archive()
+	assert.NoError(t, err)
+	assert.NoError(t, todoManager.CreateList("Home"))
+
+	lists, err := todoManager.Lists()
+	assert.NoError(t, err)
+	assert.Equal(t, []ListInfo{
+		{Name: "Home"},
+		{Name: "Work", Pending: 2, Archived: 1, Current: true},
+	}, lists)
+
+	assert.EqualError(t, todoManager.CreateList("Home"), "List \"Home\" already exists")
+	assert.EqualError(t, todoManager.CreateList("HomeArchive"), "Invalid list name \"HomeArchive\", it can't end with \"Archive\"")
+}
+
+func TestRenameAndCloneList(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, listsTodos, withTestBolt(t, "Work"), withTestJournal(0))
+	_, err := todoManager.Archive()
+	assert.NoError(t, err)
+
+	assert.NoError(t, todoManager.CloneList("Work", "Client"))
+	assert.NoError(t, todoManager.RenameList("Client", "Acme"))
+	assert.EqualError(t, todoManager.RenameList("Client", "Other"), "List \"Client\" does not exist")
+	assert.EqualError(t, todoManager.CloneList("Work", "Acme"), "List \"Acme\" already exists")
+	assert.EqualError(t, todoManager.RenameList("WorkArchive", "Other"), "List \"WorkArchive\" does not exist")
+
+	lists, err := todoManager.Lists()
+	assert.NoError(t, err)
+	assert.Equal(t, []ListInfo{
+		{Name: "Acme", Pending: 2, Archived: 1},
+		{Name: "Work", Pending: 2, Archived: 1, Current: true},
+	}, lists)
+
+	// a cloned list keeps the IDs and the sequence
+	acme := &BoltStorage{Bucket: []byte("Acme"), Path: todoManager.Storage.(*BoltStorage).Path}
+	defer acme.Close()
+	todo, err := acme.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Call client @phone", todo.String())
+	assert.NoError(t, acme.Create(todo))
+	assert.Equal(t, 4, todo.TodoID)
+}
+
+func TestDeleteList(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, listsTodos, withTestBolt(t, "Work"), withTestJournal(0))
+	assert.NoError(t, todoManager.CloneList("Work", "Old"))
+
+	assert.EqualError(t, todoManager.DeleteList("Old", false), "List \"Old\" has 3 todos, delete it with --force to remove them")
+	assert.EqualError(t, todoManager.DeleteList("Work", true), "Can't delete list \"Work\" while using it")
+	assert.EqualError(t, todoManager.DeleteList("Missing", true), "List \"Missing\" does not exist")
+	assert.NoError(t, todoManager.DeleteList("Old", true))
+
+	lists, err := todoManager.Lists()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(lists))
+}
+
+func TestMove(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, listsTodos, withTestBolt(t, "Work"), withTestJournal(0))
+	assert.NoError(t, todoManager.CreateList("Home"))
+	home := &BoltStorage{Bucket: []byte("Home"), Path: todoManager.Storage.(*BoltStorage).Path}
+	defer home.Close()
+
+	newID, err := todoManager.Move(2, "Home")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, newID)
+
+	todo, err := home.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Call client @phone", todo.String())
+	_, err = todoManager.Storage.Get(2)
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = todoManager.Move(1, "Work")
+	assert.EqualError(t, err, "Todo ID 1 is already in list \"Work\"")
+	_, err = todoManager.Move(1, "Missing")
+	assert.EqualError(t, err, "List \"Missing\" does not exist")
+	_, err = todoManager.Move(1, "WorkArchive")
+	assert.EqualError(t, err, "List \"WorkArchive\" does not exist")
+	_, err = todoManager.Move(9, "Home")
+	assert.Equal(t, ErrNotFound, err)
+
+	// the move is undone in both lists
+	entries, err := todoManager.Undo(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "move", entries[0].Operation)
+	todo, err = todoManager.Storage.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Call client @phone", todo.String())
+	_, err = home.Get(1)
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = todoManager.Redo(1)
+	assert.NoError(t, err)
+	_, err = home.Get(1)
+	assert.NoError(t, err)
+}
+
+func TestListsNeedBolt(t *testing.T) {
+	todoManager := NewTodoManager(WithMemoryStorage())
+
+	_, err := todoManager.Lists()
+	assert.Equal(t, errNoLists, err)
+	_, err = todoManager.Move(1, "Home")
+	assert.Equal(t, errNoLists, err)
+}
+
+func TestMoveSessions(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, listsTodos, withTestBolt(t, "Work"), withTestJournal(0))
+	dir := filepath.Dir(todoManager.Storage.(*BoltStorage).Path)
+	todoManager.TimeLog = &TimeLog{Path: filepath.Join(dir, "Work.time"), List: "Work"}
+	assert.NoError(t, todoManager.CreateList("Home"))
+
+	_, err := todoManager.Start(2)
+	assert.NoError(t, err)
+	_, err = todoManager.Start(1)
+	assert.NoError(t, err)
+
+	homeLog := &TimeLog{Path: filepath.Join(dir, "Home.time"), List: "Home"}
+	newID, err := todoManager.Move(2, "Home", MoveTimeLog(homeLog))
+	assert.NoError(t, err)
+
+	// the sessions follow the todo to its new list and ID
+	sessions, err := homeLog.Sessions()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sessions))
+	assert.Equal(t, newID, sessions[0].TodoID)
+	assert.Equal(t, true, sessions[0].Active())
+
+	sessions, err = todoManager.TimeLog.Sessions()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sessions))
+	assert.Equal(t, 1, sessions[0].TodoID)
+
+	// lists sharing a time log relabel the sessions, so the todo of the old list with the new ID
+	// doesn't get them
+	_, err = todoManager.Move(1, "Home", MoveTimeLog(todoManager.TimeLog))
+	assert.NoError(t, err)
+	active, err := todoManager.ActiveSessions()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(active))
+
+	sessions, err = (&TimeLog{Path: todoManager.TimeLog.Path, List: "Home"}).Sessions()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sessions))
+	assert.Equal(t, newID+1, sessions[0].TodoID)
+}
+
+func TestMoveLinks(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, listsTodos, withTestBolt(t, "Work"), withTestJournal(0))
+	assert.NoError(t, todoManager.CreateList("Home"))
+	_, err := todoManager.Add("Book the venue parent:1")
+	assert.NoError(t, err)
+	_, err = todoManager.Add("Send the invites blocks:2")
+	assert.NoError(t, err)
+
+	// neither a linked todo nor a todo others link to can move
+	_, err = todoManager.Move(1, "Home")
+	assert.EqualError(t, err, "Todo ID 1 is linked to other todos, remove its parent:, dep: and blocks: links first")
+	_, err = todoManager.Move(4, "Home")
+	assert.Error(t, err)
+	_, err = todoManager.Move(2, "Home")
+	assert.Error(t, err)
+	_, err = todoManager.Move(5, "Home")
+	assert.Error(t, err)
+
+	items, err := todoManager.Storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(items))
+
+	assert.NoError(t, todoManager.RemoveAttribute(5, "blocks"))
+	_, err = todoManager.Move(5, "Home")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,183 @@
+package gotodo
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryStorage implements Storage, keeping items in memory. Like BoltStorage, IDs come from a
+// sequence that only ever increases, so the ID of a deleted todo is never handed out again, and
+// a missing ID is ErrNotFound. The zero value is an empty storage ready to use.
+type MemoryStorage struct {
+	mu    sync.Mutex
+	items memoryTodos
+}
+
+// Create inserts a new *Todo
+func (me *MemoryStorage) Create(todo *Todo) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return me.items.Create(todo)
+}
+
+// Get retrieves the *Todo identified by todoID
+func (me *MemoryStorage) Get(todoID int) (*Todo, error) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return me.items.Get(todoID)
+}
+
+// List reads all Todos, ordered by ID
+func (me *MemoryStorage) List() (TodoList, error) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return me.items.List()
+}
+
+// Update modifies the *Todo identified by todoID
+func (me *MemoryStorage) Update(todoID int, todo *Todo) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return me.items.Update(todoID, todo)
+}
+
+// Delete removes the *Todo identified by todoID
+func (me *MemoryStorage) Delete(todoID int) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return me.items.Delete(todoID)
+}
+
+// Restore recreates a deleted *Todo under its original TodoID
+func (me *MemoryStorage) Restore(todo *Todo) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return me.items.Restore(todo)
+}
+
+// Batch runs fn on a copy of the items, which replaces them if fn succeeds and is discarded if it
+// returns an error
+func (me *MemoryStorage) Batch(fn func(tx Storage) error) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	tx := me.items.clone()
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+
+	me.items = *tx
+	return nil
+}
+
+// memoryTodos implements Storage over todo.txt strings held by ID. Todos are stored as strings
+// so changes to a *Todo aren't saved until it is updated.
+type memoryTodos struct {
+	todos    map[int]string
+	sequence int
+}
+
+// clone returns a copy of the items that can be changed independently
+func (me *memoryTodos) clone() *memoryTodos {
+	tx := &memoryTodos{todos: make(map[int]string, len(me.todos)), sequence: me.sequence}
+	for todoID, todoStr := range me.todos {
+		tx.todos[todoID] = todoStr
+	}
+
+	return tx
+}
+
+// Create inserts a new *Todo under the next ID in the sequence
+func (me *memoryTodos) Create(todo *Todo) error {
+	if me.todos == nil {
+		me.todos = make(map[int]string)
+	}
+
+	me.sequence++
+	todo.TodoID = me.sequence
+	me.todos[todo.TodoID] = todo.String()
+
+	return nil
+}
+
+// Get retrieves the *Todo identified by todoID
+func (me *memoryTodos) Get(todoID int) (*Todo, error) {
+	todoStr, ok := me.todos[todoID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	todo := FromString(todoStr)
+	todo.TodoID = todoID
+
+	return todo, nil
+}
+
+// List reads all Todos, ordered by ID
+func (me *memoryTodos) List() (TodoList, error) {
+	items := make(TodoList, 0, len(me.todos))
+
+	for todoID, todoStr := range me.todos {
+		todo := FromString(todoStr)
+		todo.TodoID = todoID
+		items = append(items, todo)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].TodoID < items[j].TodoID
+	})
+
+	return items, nil
+}
+
+// Update modifies the *Todo identified by todoID
+func (me *memoryTodos) Update(todoID int, todo *Todo) error {
+	// make sure the ID exists before working with it
+	if _, ok := me.todos[todoID]; !ok {
+		return ErrNotFound
+	}
+
+	me.todos[todoID] = todo.String()
+
+	return nil
+}
+
+// Delete removes the *Todo identified by todoID
+func (me *memoryTodos) Delete(todoID int) error {
+	// make sure the ID exists before working with it
+	if _, ok := me.todos[todoID]; !ok {
+		return ErrNotFound
+	}
+
+	delete(me.todos, todoID)
+
+	return nil
+}
+
+// Restore recreates a deleted *Todo under its original TodoID. The sequence is moved past it so a
+// new Todo never takes its ID.
+func (me *memoryTodos) Restore(todo *Todo) error {
+	// make sure the ID is free before working with it
+	if _, ok := me.todos[todo.TodoID]; ok {
+		return errExists
+	} else if todo.TodoID < 1 {
+		return ErrNotFound
+	}
+
+	if me.todos == nil {
+		me.todos = make(map[int]string)
+	}
+	if todo.TodoID > me.sequence {
+		me.sequence = todo.TodoID
+	}
+	me.todos[todo.TodoID] = todo.String()
+
+	return nil
+}
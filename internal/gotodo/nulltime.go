@@ -33,3 +33,8 @@ func (me NullTime) Display() string {
 	}
 	return ""
 }
+
+// dateOf returns midnight UTC on the calendar day of a time, matching dates parsed from todo.txt
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,315 @@
+package gotodo
+
+import "time"
+
+// operation tracks the changes a single TodoManager method makes to storage, so they can be
+// written to the journal as one entry and undone together. Inside a batch, storage and archived
+// are the batch's view of the TodoManager's storage and archive.
+type operation struct {
+	tm        *TodoManager
+	name      string
+	storage   Storage
+	archived  Storage
+	snapshots map[int]string
+	changes   []JournalChange
+	removed   []int
+}
+
+// begin starts a new operation
+func (tm *TodoManager) begin(name string) *operation {
+	return &operation{
+		tm:        tm,
+		name:      name,
+		storage:   tm.Storage,
+		archived:  tm.ArchiveStorage,
+		snapshots: make(map[int]string),
+		changes:   make([]JournalChange, 0),
+	}
+}
+
+// batch runs fn as a single operation. Where the storage supports it, every change fn makes is
+// saved at once if fn succeeds and none are saved if it returns an error. The journal entry is
+// only recorded once storage and archive are both saved, and batch reports an error rather than
+// success when recording it fails, leaving the saved changes in place but not undoable.
+func (tm *TodoManager) batch(name string, fn func(op *operation) error) error {
+	op := tm.begin(name)
+
+	err := batchStorage(tm.Storage, tm.ArchiveStorage, func(storage Storage, archive Storage) error {
+		op.storage = storage
+		op.archived = archive
+		if err := fn(op); err != nil {
+			return err
+		}
+
+		return op.unlinkRemoved()
+	})
+	if err != nil {
+		return err
+	}
+
+	return op.commit()
+}
+
+// batchStorage runs fn inside a batch of both storage and archive. Bolt buckets share a single
+// database, so they are batched in one transaction rather than two nested ones.
+//
+// Other storages are nested with the archive outermost, so storage is saved first and the archive
+// last. A failure between the two saves, such as a full disk while writing done.txt, leaves the
+// todos fn archived missing from the archive, and no journal entry is recorded for them.
+func batchStorage(storage Storage, archive Storage, fn func(storage Storage, archive Storage) error) error {
+	if boltStorage, ok := storage.(*BoltStorage); ok {
+		if boltArchive, ok := archive.(*BoltStorage); ok {
+			return boltStorage.batchWith(boltArchive, fn)
+		}
+	}
+
+	return batchOne(archive, func(archive Storage) error {
+		return batchOne(storage, func(storage Storage) error {
+			return fn(storage, archive)
+		})
+	})
+}
+
+// batchOne runs fn inside a batch of storage, or directly on storage if it is not a Batcher
+func batchOne(storage Storage, fn func(storage Storage) error) error {
+	if batcher, ok := storage.(Batcher); ok {
+		return batcher.Batch(fn)
+	}
+
+	return fn(storage)
+}
+
+// get retrieves a Todo from storage and remembers its state before any changes
+func (op *operation) get(todoID int) (*Todo, error) {
+	todo, err := op.storage.Get(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := op.snapshots[todoID]; !ok {
+		op.snapshots[todoID] = todo.String()
+	}
+
+	return todo, nil
+}
+
+// list reads all Todos from storage and remembers their state before any changes
+func (op *operation) list() (TodoList, error) {
+	items, err := op.storage.List()
+	if err != nil {
+		return items, err
+	}
+
+	for _, todo := range items {
+		if _, ok := op.snapshots[todo.TodoID]; !ok {
+			op.snapshots[todo.TodoID] = todo.String()
+		}
+	}
+
+	return items, nil
+}
+
+// create inserts a new Todo into storage, with relative due dates made absolute
+func (op *operation) create(todo *Todo) error {
+	todo.normalizeDates(time.Now())
+
+	err := op.storage.Create(todo)
+	if err != nil {
+		return err
+	}
+
+	op.snapshots[todo.TodoID] = todo.String()
+	op.changes = append(op.changes, JournalChange{TodoID: todo.TodoID, After: todo.String()})
+
+	return op.checkLinks(todo)
+}
+
+// update saves a Todo retrieved with get, with relative due dates made absolute
+func (op *operation) update(todoID int, todo *Todo) error {
+	err := op.save(todoID, todo)
+	if err != nil {
+		return err
+	}
+
+	return op.checkLinks(todo)
+}
+
+// save saves a Todo retrieved with get like update, without checking its links for cycles
+func (op *operation) save(todoID int, todo *Todo) error {
+	todo.normalizeDates(time.Now())
+
+	before := op.snapshots[todoID]
+	after := todo.String()
+
+	err := op.storage.Update(todoID, todo)
+	if err != nil {
+		return err
+	}
+
+	op.snapshots[todoID] = after
+	op.changes = append(op.changes, JournalChange{TodoID: todoID, Before: before, After: after})
+
+	return nil
+}
+
+// checkLinks returns a *CycleError if a Todo just saved links to itself. Only a todo with links
+// can close a cycle, so todos without them aren't checked.
+func (op *operation) checkLinks(todo *Todo) error {
+	if !todo.hasLinks() {
+		return nil
+	}
+
+	items, err := op.storage.List()
+	if err != nil {
+		return err
+	}
+
+	return NewTodoLinks(items).checkCycle(todo.TodoID)
+}
+
+// delete removes a Todo from storage
+func (op *operation) delete(todoID int) error {
+	before, ok := op.snapshots[todoID]
+	if !ok {
+		todo, err := op.get(todoID)
+		if err != nil {
+			return err
+		}
+		before = todo.String()
+	}
+
+	err := op.storage.Delete(todoID)
+	if err != nil {
+		return err
+	}
+
+	delete(op.snapshots, todoID)
+	op.changes = append(op.changes, JournalChange{TodoID: todoID, Before: before})
+	op.removed = append(op.removed, todoID)
+
+	return nil
+}
+
+// archive moves a Todo from storage to the archive. The archive assigns the Todo a new ID.
+func (op *operation) archive(todo *Todo) error {
+	if op.archived == nil {
+		return errNoArchive
+	}
+
+	todoID := todo.TodoID
+	err := op.archived.Create(todo)
+	if err != nil {
+		return err
+	}
+
+	change := JournalChange{Archive: true, TodoID: todo.TodoID, After: todo.String()}
+	op.changes = append(op.changes, change)
+
+	return op.delete(todoID)
+}
+
+// unlinkRemoved drops the todos the operation removed or archived from the parent:, dep: and
+// blocks: links of the todos left in storage, in a single pass once the operation is done
+func (op *operation) unlinkRemoved() error {
+	if len(op.removed) == 0 {
+		return nil
+	}
+
+	items, err := op.list()
+	if err != nil {
+		return err
+	}
+
+	for _, todo := range items {
+		changed := false
+		for _, todoID := range op.removed {
+			if todo.linksTo(todoID) && todo.unlink(todoID) {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		// Dropping links can't close a cycle, so they aren't checked again
+		err = op.save(todo.TodoID, todo)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createArchived inserts a new Todo straight into the archive
+func (op *operation) createArchived(todo *Todo) error {
+	if op.archived == nil {
+		return errNoArchive
+	}
+
+	err := op.archived.Create(todo)
+	if err != nil {
+		return err
+	}
+
+	op.changes = append(op.changes, JournalChange{Archive: true, TodoID: todo.TodoID, After: todo.String()})
+
+	return nil
+}
+
+// listStorage returns the Storage of another list, within the same batch
+func (op *operation) listStorage(list string) (Storage, error) {
+	return listStorage(op.storage, list)
+}
+
+// createIn inserts a new Todo into storage, the Storage of another list returned by listStorage
+func (op *operation) createIn(list string, storage Storage, todo *Todo) error {
+	err := storage.Create(todo)
+	if err != nil {
+		return err
+	}
+
+	op.changes = append(op.changes, JournalChange{List: list, TodoID: todo.TodoID, After: todo.String()})
+
+	return nil
+}
+
+// commit writes the changes made by the operation to the journal
+func (op *operation) commit() error {
+	if op.tm.Journal == nil || len(op.changes) == 0 {
+		return nil
+	}
+
+	return op.tm.Journal.Record(JournalEntry{
+		Time:      time.Now(),
+		Operation: op.name,
+		Changes:   op.changes,
+	})
+}
+
+// modify applies fn to the Todo identified by todoID and saves it
+func (op *operation) modify(todoID int, fn func(todo *Todo) error) error {
+	todo, err := op.get(todoID)
+	if err != nil {
+		return err
+	}
+
+	err = fn(todo)
+	if err != nil {
+		return err
+	}
+
+	return op.update(todoID, todo)
+}
+
+// modify applies fn to the Todo identified by todoID, saves it and records the change
+func (tm *TodoManager) modify(name string, todoID int, fn func(todo *Todo) error) error {
+	return singleResult(tm.modifyAll(name, []int{todoID}, fn))
+}
+
+// modifyAll applies fn to every Todo identified by todoIDs, in a single batch
+func (tm *TodoManager) modifyAll(name string, todoIDs []int, fn func(todo *Todo) error) ([]BulkResult, error) {
+	return tm.bulk(name, todoIDs, func(op *operation, todoID int) error {
+		return op.modify(todoID, fn)
+	})
+}
@@ -20,6 +20,36 @@ func letterIndex(char string) int {
 	return -1
 }
 
+// token is a word in a todo.txt line, with the byte offsets where it starts and ends
+type token struct {
+	text  string
+	start int
+	end   int
+}
+
+// tokenize splits a todo.txt line into words separated by spaces or tabs. Each token keeps its
+// position, so parts of the line can be sliced out exactly as they were written.
+func tokenize(line string) []token {
+	tokens := make([]token, 0)
+	start := -1
+
+	for idx := 0; idx <= len(line); idx++ {
+		if idx < len(line) && line[idx] != ' ' && line[idx] != '\t' {
+			if start == -1 {
+				start = idx
+			}
+			continue
+		}
+
+		if start != -1 {
+			tokens = append(tokens, token{text: line[start:idx], start: start, end: idx})
+			start = -1
+		}
+	}
+
+	return tokens
+}
+
 // isCompleteToken determines whether or not a token is a todo.txt complete flag
 func isCompleteToken(token string) bool {
 	return len(token) == 1 && string(token[0]) == "x"
@@ -82,16 +112,13 @@ func parsePriority(arg string) int {
 
 // unparsePriority converts a base-26 number to a todo.txt priority score
 func unparsePriority(priority int) string {
+	// Letters are digits from 1 to 26 with no zero, so shift each one down before dividing
 	quotient := priority
-	remainder := 0
 	scores := make([]string, 0)
-	for {
-		remainder = quotient % 26
+	for quotient > 0 {
+		quotient--
+		scores = append(scores, letters[quotient%26])
 		quotient = quotient / 26
-		scores = append(scores, letters[remainder-1])
-		if quotient == 0 {
-			break
-		}
 	}
 
 	for i := len(scores)/2 - 1; i >= 0; i-- {
@@ -102,6 +129,16 @@ func unparsePriority(priority int) string {
 	return strings.Join(scores, "")
 }
 
+// PriorityString converts a priority score into its todo.txt letters, or an empty string when
+// the priority is not set
+func PriorityString(priority int) string {
+	if priority <= 0 {
+		return ""
+	}
+
+	return unparsePriority(priority)
+}
+
 // parseDate determines whether or not a string is formatted according to TimeFormat.
 // It returns a valid NullTime if the string is formatted properly, or invalid NullTime
 func parseDate(arg string) NullTime {
@@ -115,7 +152,7 @@ func parseTags(parts []string) (Tags, Tags) {
 	var elem void
 
 	for _, part := range parts {
-		if len(part) == 0 {
+		if len(part) < 2 {
 			continue
 		}
 		switch string(part[0]) {
@@ -128,3 +165,21 @@ func parseTags(parts []string) (Tags, Tags) {
 
 	return projects, contexts
 }
+
+// parseAttributes extracts todo.txt key:value attributes from a slice of strings. If the
+// attribute has multiple colons, the first part is the key and the rest is the value. Words with
+// an empty key or value, and URLs like http://example.com, aren't attributes.
+func parseAttributes(parts []string) Attributes {
+	attributes := make(Attributes)
+
+	for _, part := range parts {
+		idx := strings.Index(part, ":")
+		if idx < 1 || idx == len(part)-1 || strings.HasPrefix(part[idx+1:], "//") {
+			continue
+		}
+
+		attributes[part[:idx]] = part[idx+1:]
+	}
+
+	return attributes
+}
@@ -0,0 +1,403 @@
+package gotodo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QueryNode is a node in a parsed query. Matching a Todo against the root node tells whether the
+// Todo satisfies the entire query.
+type QueryNode interface {
+	Match(todo *Todo) bool
+}
+
+// andNode matches Todos that match both of its children
+type andNode struct {
+	left  QueryNode
+	right QueryNode
+}
+
+// Match checks a Todo against both children
+func (n andNode) Match(todo *Todo) bool {
+	return n.left.Match(todo) && n.right.Match(todo)
+}
+
+// orNode matches Todos that match either of its children
+type orNode struct {
+	left  QueryNode
+	right QueryNode
+}
+
+// Match checks a Todo against either child
+func (n orNode) Match(todo *Todo) bool {
+	return n.left.Match(todo) || n.right.Match(todo)
+}
+
+// notNode matches Todos that do not match its child
+type notNode struct {
+	child QueryNode
+}
+
+// Match inverts the match of the child
+func (n notNode) Match(todo *Todo) bool {
+	return !n.child.Match(todo)
+}
+
+// projectNode matches Todos tagged with a project
+type projectNode string
+
+// Match checks a Todo for the project
+func (n projectNode) Match(todo *Todo) bool {
+	return todo.hasProject(string(n))
+}
+
+// contextNode matches Todos tagged with a context
+type contextNode string
+
+// Match checks a Todo for the context
+func (n contextNode) Match(todo *Todo) bool {
+	return todo.hasContext(string(n))
+}
+
+// textNode matches Todos whose description contains some text, ignoring case
+type textNode string
+
+// Match checks a Todo description for the text
+func (n textNode) Match(todo *Todo) bool {
+	return strings.Contains(strings.ToLower(todo.Description), string(n))
+}
+
+// attributeExistsNode matches Todos that have an attribute, whatever its value
+type attributeExistsNode string
+
+// Match checks a Todo for the attribute
+func (n attributeExistsNode) Match(todo *Todo) bool {
+	return todo.hasAttribute(string(n))
+}
+
+// compareNode matches Todos by comparing a field or attribute against a value
+type compareNode struct {
+	field     string
+	op        string
+	value     string
+	attribute bool
+}
+
+// queryOperators are the comparison operators, longest first so "<=" is not read as "<"
+var queryOperators = []string{"<=", ">=", "!=", "<", ">", "="}
+
+// queryDateFields map the names of date fields to their value on a Todo
+var queryDateFields = map[string]func(*Todo) NullTime{
+	"due":       func(t *Todo) NullTime { return t.DueDate },
+	"created":   func(t *Todo) NullTime { return t.CreationDate },
+	"completed": func(t *Todo) NullTime { return t.CompletionDate },
+	"threshold": func(t *Todo) NullTime { return t.ThresholdDate },
+}
+
+// Match compares the field or attribute of a Todo against the node value
+func (n compareNode) Match(todo *Todo) bool {
+	if getDate, ok := queryDateFields[n.field]; ok && !n.attribute {
+		date := getDate(todo)
+		if !date.Valid {
+			return n.op == "!="
+		}
+		return compareValues(date.Display(), n.op, n.value)
+	}
+
+	if n.field == "pri" && !n.attribute {
+		if todo.Priority == 0 {
+			return n.op == "!="
+		}
+		// A higher priority has a lower score, so flip the comparison: pri>=B matches A and B
+		return compareInts(parsePriority(n.value), n.op, todo.Priority)
+	}
+
+	value, ok := todo.Attributes[n.field]
+	if !ok {
+		return false
+	}
+
+	return compareValues(value, n.op, n.value)
+}
+
+// compareInts compares two integers with a query operator
+func compareInts(a int, op string, b int) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "!=":
+		return a != b
+	}
+
+	return a == b
+}
+
+// compareValues compares two strings with a query operator. Numbers are compared numerically and
+// everything else, including YYYY-MM-DD dates, is compared as text.
+func compareValues(a string, op string, b string) bool {
+	cmp := strings.Compare(a, b)
+
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		cmp = 0
+		if x < y {
+			cmp = -1
+		} else if x > y {
+			cmp = 1
+		}
+	}
+
+	return compareInts(cmp, op, 0)
+}
+
+// queryParser is a recursive descent parser for the query language. In order of precedence:
+//
+//	expr    = and { "or" and }
+//	and     = not { ["and"] not }
+//	not     = "not" not | primary
+//	primary = "(" expr ")" | term
+//
+// A term is a +project, an @context, a comparison such as due<2020-06-01, pri>=B or estimate:>2,
+// an attribute such as key:value or key:*, or any other word to find in the description.
+type queryParser struct {
+	tokens []string
+	pos    int
+}
+
+// ParseQuery parses a query string into a tree of QueryNodes
+func ParseQuery(query string) (QueryNode, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("Empty query")
+	}
+
+	parser := &queryParser{tokens: tokens}
+	node, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.pos < len(parser.tokens) {
+		return nil, fmt.Errorf("Unexpected \"%s\" in query", parser.tokens[parser.pos])
+	}
+
+	return node, nil
+}
+
+// tokenizeQuery splits a query into words, parentheses and quoted phrases
+func tokenizeQuery(query string) ([]string, error) {
+	tokens := make([]string, 0)
+	word := ""
+	quoted := false
+
+	for _, char := range query {
+		switch {
+		case quoted && char == '"':
+			tokens = append(tokens, "\""+word+"\"")
+			word = ""
+			quoted = false
+		case quoted:
+			word += string(char)
+		case char == '"' && word == "":
+			quoted = true
+		case char == '(' || char == ')' || char == ' ' || char == '\t':
+			if word != "" {
+				tokens = append(tokens, word)
+				word = ""
+			}
+			if char == '(' || char == ')' {
+				tokens = append(tokens, string(char))
+			}
+		default:
+			word += string(char)
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("Missing closing quote in query")
+	}
+
+	if word != "" {
+		tokens = append(tokens, word)
+	}
+
+	return tokens, nil
+}
+
+// peek returns the next token, lowercased, without consuming it
+func (p *queryParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return strings.ToLower(p.tokens[p.pos])
+}
+
+func (p *queryParser) parseOr() (QueryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "or" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (QueryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		next := p.peek()
+		if next == "" || next == "or" || next == ")" {
+			return left, nil
+		}
+
+		// "and" is optional between terms
+		if next == "and" {
+			p.pos++
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *queryParser) parseNot() (QueryNode, error) {
+	if p.peek() == "not" {
+		p.pos++
+		child, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{child}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (QueryNode, error) {
+	switch p.peek() {
+	case "":
+		return nil, fmt.Errorf("Unexpected end of query")
+	case ")", "and", "or":
+		return nil, fmt.Errorf("Unexpected \"%s\" in query", p.tokens[p.pos])
+	case "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("Missing closing parenthesis in query")
+		}
+		p.pos++
+		return node, nil
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+
+	return parseQueryTerm(token)
+}
+
+// parseQueryTerm converts a single query word into a QueryNode
+func parseQueryTerm(token string) (QueryNode, error) {
+	if strings.HasPrefix(token, "\"") {
+		return textNode(strings.ToLower(strings.Trim(token, "\""))), nil
+	}
+
+	if len(token) > 1 && token[0] == '+' {
+		return projectNode(token[1:]), nil
+	}
+
+	if len(token) > 1 && token[0] == '@' {
+		return contextNode(token[1:]), nil
+	}
+
+	// key:value and key:<op>value address attributes
+	if idx := strings.Index(token, ":"); idx > 0 {
+		key := token[:idx]
+		rest := token[idx+1:]
+
+		if rest == "*" {
+			return attributeExistsNode(key), nil
+		}
+
+		for _, op := range queryOperators {
+			if strings.HasPrefix(rest, op) {
+				return newCompareNode(key, op, rest[len(op):], true)
+			}
+		}
+
+		return newCompareNode(key, "=", rest, true)
+	}
+
+	// field<op>value addresses dates, priority and attributes
+	for _, op := range queryOperators {
+		if idx := strings.Index(token, op); idx > 0 {
+			return newCompareNode(token[:idx], op, token[idx+len(op):], false)
+		}
+	}
+
+	return textNode(strings.ToLower(token)), nil
+}
+
+// newCompareNode validates a comparison. Comparisons written as key:value address attributes,
+// except for due which is kept in sync with DueDate. Other comparisons address the due, created,
+// completed and threshold dates or the priority, falling back to attributes for any other name.
+func newCompareNode(field string, op string, value string, attribute bool) (QueryNode, error) {
+	if value == "" {
+		return nil, fmt.Errorf("Missing value to compare %s against", field)
+	}
+
+	name := strings.ToLower(field)
+	if name == "priority" {
+		name = "pri"
+	}
+
+	if attribute && name != "due" {
+		return compareNode{field, op, value, true}, nil
+	}
+
+	if _, ok := queryDateFields[name]; ok {
+		if !parseDate(value).Valid {
+			return nil, fmt.Errorf("Invalid date \"%s\" for %s, use YYYY-MM-DD", value, name)
+		}
+		return compareNode{name, op, value, false}, nil
+	}
+
+	if name == "pri" {
+		if !IsPriorityString(value) {
+			return nil, fmt.Errorf("Invalid priority \"%s\"", value)
+		}
+		return compareNode{name, op, strings.ToUpper(value), false}, nil
+	}
+
+	return compareNode{field, op, value, true}, nil
+}
@@ -0,0 +1,126 @@
+package gotodo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertQueryMatch(t *testing.T, query string, todoStr string, expected bool) {
+	node, err := ParseQuery(query)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, node.Match(FromString(todoStr)), "%s matching %s", query, todoStr)
+}
+
+func TestTokenizeQuery(t *testing.T) {
+	tokens, err := tokenizeQuery(`+gotodo and (@work or "unit tests") not due<2020-05-01`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"+gotodo", "and", "(", "@work", "or", `"unit tests"`, ")", "not", "due<2020-05-01",
+	}, tokens)
+
+	_, err = tokenizeQuery(`"unit tests`)
+	assert.Error(t, err)
+}
+
+func TestQueryTags(t *testing.T) {
+	todoStr := "Work on unit tests @codehealth +gotodo"
+
+	assertQueryMatch(t, "+gotodo", todoStr, true)
+	assertQueryMatch(t, "+other", todoStr, false)
+	assertQueryMatch(t, "@codehealth", todoStr, true)
+	assertQueryMatch(t, "@work", todoStr, false)
+}
+
+func TestQueryText(t *testing.T) {
+	todoStr := "Work on unit tests @codehealth +gotodo"
+
+	assertQueryMatch(t, "UNIT", todoStr, true)
+	assertQueryMatch(t, `"on unit"`, todoStr, true)
+	assertQueryMatch(t, `"unit on"`, todoStr, false)
+}
+
+func TestQueryBoolean(t *testing.T) {
+	todoStr := "Work on unit tests @codehealth +gotodo"
+
+	assertQueryMatch(t, "+gotodo and @codehealth", todoStr, true)
+	assertQueryMatch(t, "+gotodo @codehealth", todoStr, true)
+	assertQueryMatch(t, "+gotodo and @work", todoStr, false)
+	assertQueryMatch(t, "+gotodo and (@work or @codehealth)", todoStr, true)
+	assertQueryMatch(t, "+gotodo AND NOT @waiting", todoStr, true)
+	assertQueryMatch(t, "not +gotodo or @codehealth", todoStr, true)
+	assertQueryMatch(t, "not (+gotodo or @work)", todoStr, false)
+	assertQueryMatch(t, "@work or @home and +gotodo", todoStr, false)
+}
+
+func TestQueryDates(t *testing.T) {
+	todoStr := "x 2020-04-29 2020-04-28 Add parser test due:2020-05-01"
+
+	assertQueryMatch(t, "due<2020-05-02", todoStr, true)
+	assertQueryMatch(t, "due<2020-05-01", todoStr, false)
+	assertQueryMatch(t, "due<=2020-05-01", todoStr, true)
+	assertQueryMatch(t, "due:<=2020-05-01", todoStr, true)
+	assertQueryMatch(t, "due=2020-05-01", todoStr, true)
+	assertQueryMatch(t, "created>=2020-04-28 and created<2020-04-29", todoStr, true)
+	assertQueryMatch(t, "completed>2020-04-29", todoStr, false)
+	assertQueryMatch(t, "due<2020-05-02", "No due date", false)
+	assertQueryMatch(t, "due!=2020-05-02", "No due date", true)
+}
+
+func TestQueryPriority(t *testing.T) {
+	assertQueryMatch(t, "pri>=B", "(A) Urgent", true)
+	assertQueryMatch(t, "pri>=B", "(B) Important", true)
+	assertQueryMatch(t, "pri>=B", "(C) Someday", false)
+	assertQueryMatch(t, "pri<b", "(C) Someday", true)
+	assertQueryMatch(t, "priority=A", "(A) Urgent", true)
+	assertQueryMatch(t, "pri>=B", "Not prioritized", false)
+}
+
+func TestQueryAttributes(t *testing.T) {
+	todoStr := "Estimate the backlog estimate:3 owner:dkr"
+
+	assertQueryMatch(t, "estimate:>2", todoStr, true)
+	assertQueryMatch(t, "estimate:>=10", todoStr, false)
+	assertQueryMatch(t, "estimate>2", todoStr, true)
+	assertQueryMatch(t, "owner:dkr", todoStr, true)
+	assertQueryMatch(t, "owner:!=dkr", todoStr, false)
+	assertQueryMatch(t, "owner:*", todoStr, true)
+	assertQueryMatch(t, "reviewer:*", todoStr, false)
+	assertQueryMatch(t, "reviewer:dkr", todoStr, false)
+}
+
+func TestQueryErrors(t *testing.T) {
+	queries := []string{
+		"",
+		"(+gotodo",
+		"+gotodo)",
+		"+gotodo and",
+		"or @work",
+		"not",
+		"due<tomorrow",
+		"pri>=1",
+		"estimate:>",
+	}
+
+	for _, query := range queries {
+		_, err := ParseQuery(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestQueryManager(t *testing.T) {
+	todoManager := getTestTodoManager()
+
+	items, err := todoManager.Query("+gotodo and not @codehealth")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "Add parser test +gotodo due:2020-05-01", items[0].Description)
+
+	items, err = todoManager.List(TodoListFilter{Status: ListPending, Query: "+gotodo"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "Work on unit tests @codehealth +gotodo", items[0].Description)
+
+	_, err = todoManager.Query("+gotodo and")
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package gotodo
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Recurrence describes how often a Todo repeats, as set by the rec: attribute. rec:1w repeats a
+// week after the todo is completed, while the strict form rec:+1w repeats a week after the
+// previous due date. Units are d (days), b (business days), w (weeks), m (months) and y (years).
+type Recurrence struct {
+	Amount int
+	Unit   byte
+	Strict bool
+}
+
+// ParseRecurrence converts a rec: attribute value into a Recurrence
+func ParseRecurrence(value string) (Recurrence, error) {
+	var rec Recurrence
+	invalid := fmt.Errorf("Invalid recurrence \"%s\", use a number and unit like 1w or +3d", value)
+
+	if len(value) > 0 && value[0] == '+' {
+		rec.Strict = true
+		value = value[1:]
+	}
+
+	if len(value) < 1 {
+		return rec, invalid
+	}
+
+	// The amount is optional, so rec:w is the same as rec:1w
+	rec.Unit = value[len(value)-1]
+	rec.Amount = 1
+	if len(value) > 1 {
+		amount, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || amount < 1 {
+			return rec, invalid
+		}
+		rec.Amount = amount
+	}
+
+	switch rec.Unit {
+	case 'd', 'b', 'w', 'm', 'y':
+		return rec, nil
+	}
+
+	return rec, invalid
+}
+
+// Next returns the date one recurrence after from
+func (r Recurrence) Next(from time.Time) time.Time {
+	switch r.Unit {
+	case 'b':
+		next := from
+		for added := 0; added < r.Amount; {
+			next = next.AddDate(0, 0, 1)
+			if next.Weekday() != time.Saturday && next.Weekday() != time.Sunday {
+				added++
+			}
+		}
+		return next
+	case 'w':
+		return from.AddDate(0, 0, 7*r.Amount)
+	case 'm':
+		return from.AddDate(0, r.Amount, 0)
+	case 'y':
+		return from.AddDate(r.Amount, 0, 0)
+	}
+
+	return from.AddDate(0, 0, r.Amount)
+}
+
+// nextOccurrence builds the pending Todo that replaces a recurring Todo completed at completed.
+// The due date moves forward from the completion date, or from the old due date for strict
+// recurrences. A threshold date (t:) keeps the same distance from the due date. It returns nil
+// when the Todo does not recur or is already complete.
+func nextOccurrence(todo *Todo, completed time.Time) (*Todo, error) {
+	value, ok := todo.Attributes["rec"]
+	if !ok || todo.Complete {
+		return nil, nil
+	}
+
+	rec, err := ParseRecurrence(value)
+	if err != nil {
+		return nil, err
+	}
+
+	completed = dateOf(completed)
+	next := FromString(todo.String())
+	next.Complete = false
+	next.CompletionDate = InvalidTime
+	if next.CreationDate.Valid {
+		next.CreationDate = ValidTime(completed)
+	}
+
+	due := todo.DueDate
+	threshold := todo.ThresholdDate
+
+	if due.Valid {
+		base := completed
+		if rec.Strict {
+			base = due.Time
+		}
+		nextDue := rec.Next(base)
+		next.setAttribute("due", nextDue.Format(TimeFormat))
+
+		if threshold.Valid {
+			nextThreshold := nextDue.Add(threshold.Time.Sub(due.Time))
+			next.setAttribute("t", nextThreshold.Format(TimeFormat))
+		}
+	} else if threshold.Valid {
+		base := completed
+		if rec.Strict {
+			base = threshold.Time
+		}
+		next.setAttribute("t", rec.Next(base).Format(TimeFormat))
+	} else {
+		next.setAttribute("due", rec.Next(completed).Format(TimeFormat))
+	}
+
+	return next, nil
+}
@@ -0,0 +1,82 @@
+package gotodo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	rec, err := ParseRecurrence("1w")
+	assert.NoError(t, err)
+	assert.Equal(t, Recurrence{Amount: 1, Unit: 'w', Strict: false}, rec)
+
+	rec, err = ParseRecurrence("+3d")
+	assert.NoError(t, err)
+	assert.Equal(t, Recurrence{Amount: 3, Unit: 'd', Strict: true}, rec)
+
+	rec, err = ParseRecurrence("m")
+	assert.NoError(t, err)
+	assert.Equal(t, Recurrence{Amount: 1, Unit: 'm', Strict: false}, rec)
+
+	rec, err = ParseRecurrence("10b")
+	assert.NoError(t, err)
+	assert.Equal(t, Recurrence{Amount: 10, Unit: 'b', Strict: false}, rec)
+}
+
+func TestParseRecurrenceInvalid(t *testing.T) {
+	for _, value := range []string{"", "+", "1", "1x", "0d", "-1d", "+-1d", "1.5w", "d1"} {
+		_, err := ParseRecurrence(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	// 2020-05-01 is a Friday
+	from, err := time.Parse(TimeFormat, "2020-05-01")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "2020-05-04", Recurrence{Amount: 3, Unit: 'd'}.Next(from).Format(TimeFormat))
+	assert.Equal(t, "2020-05-04", Recurrence{Amount: 1, Unit: 'b'}.Next(from).Format(TimeFormat))
+	assert.Equal(t, "2020-05-08", Recurrence{Amount: 5, Unit: 'b'}.Next(from).Format(TimeFormat))
+	assert.Equal(t, "2020-05-15", Recurrence{Amount: 2, Unit: 'w'}.Next(from).Format(TimeFormat))
+	assert.Equal(t, "2020-06-01", Recurrence{Amount: 1, Unit: 'm'}.Next(from).Format(TimeFormat))
+	assert.Equal(t, "2021-05-01", Recurrence{Amount: 1, Unit: 'y'}.Next(from).Format(TimeFormat))
+}
+
+func TestNextOccurrence(t *testing.T) {
+	completed, err := time.Parse(TimeFormat, "2020-05-03")
+	assert.NoError(t, err)
+
+	next, err := nextOccurrence(FromString("(A) 2020-04-28 Water plants rec:1w due:2020-05-01"), completed)
+	assert.NoError(t, err)
+	assert.Equal(t, "(A) 2020-05-03 Water plants rec:1w due:2020-05-10", next.String())
+	assert.Equal(t, "2020-05-10", next.DueDate.Display())
+	assert.Equal(t, false, next.Complete)
+
+	next, err = nextOccurrence(FromString("Pay rent rec:+1m due:2020-05-01 t:2020-04-26"), completed)
+	assert.NoError(t, err)
+	assert.Equal(t, "Pay rent rec:+1m due:2020-06-01 t:2020-05-27", next.String())
+
+	next, err = nextOccurrence(FromString("Review inbox t:2020-05-01 rec:2d"), completed)
+	assert.NoError(t, err)
+	assert.Equal(t, "Review inbox t:2020-05-05 rec:2d", next.String())
+
+	next, err = nextOccurrence(FromString("Stretch rec:1d"), completed)
+	assert.NoError(t, err)
+	assert.Equal(t, "Stretch rec:1d due:2020-05-04", next.String())
+}
+
+func TestNextOccurrenceNone(t *testing.T) {
+	next, err := nextOccurrence(FromString("Water plants due:2020-05-01"), time.Now())
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	next, err = nextOccurrence(FromString("x 2020-05-01 Water plants rec:1w"), time.Now())
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	_, err = nextOccurrence(FromString("Water plants rec:often"), time.Now())
+	assert.Error(t, err)
+}
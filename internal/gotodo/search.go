@@ -0,0 +1,221 @@
+package gotodo
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchMatch is the position of a match in a Todo description, as byte offsets
+type SearchMatch struct {
+	Start int
+	End   int
+}
+
+// SearchResult is a Todo found by Search. Archived is set for todos found in the archive, whose
+// TodoID refers to the archive rather than the main storage.
+type SearchResult struct {
+	Todo     *Todo
+	Archived bool
+	Score    float64
+	Matches  []SearchMatch
+}
+
+// searchTerm is a word or quoted phrase to search for. Prefix terms, written with a trailing *,
+// match the start of the last word instead of the whole word.
+type searchTerm struct {
+	words  []string
+	prefix bool
+}
+
+// searchPriorityWeight is how much a priority of A adds to a score. Lower priorities add less.
+const searchPriorityWeight = 0.5
+
+// errEmptySearch is returned when a search has no terms
+var errEmptySearch = errors.New("Search for at least one word")
+
+// parseSearch splits a search into terms. Words in quotes are searched for as a phrase.
+func parseSearch(search string) ([]searchTerm, error) {
+	tokens, err := tokenizeQuery(search)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := make([]searchTerm, 0, len(tokens))
+	for _, tok := range tokens {
+		tok = strings.Trim(tok, "\"")
+		prefix := strings.HasSuffix(tok, "*")
+
+		words := make([]string, 0)
+		for _, word := range searchWords(strings.TrimSuffix(tok, "*")) {
+			words = append(words, word.text)
+		}
+
+		if len(words) > 0 {
+			terms = append(terms, searchTerm{words: words, prefix: prefix})
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil, errEmptySearch
+	}
+
+	return terms, nil
+}
+
+// searchWords splits text into lowercased words made of letters and digits, keeping the position
+// of every word in text
+func searchWords(text string) []token {
+	words := make([]token, 0)
+	start := -1
+
+	for idx, char := range text + " " {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) {
+			if start == -1 {
+				start = idx
+			}
+			continue
+		}
+
+		if start != -1 {
+			words = append(words, token{text: strings.ToLower(text[start:idx]), start: start, end: idx})
+			start = -1
+		}
+	}
+
+	return words
+}
+
+// find returns every place the term appears in words
+func (term searchTerm) find(words []token) []SearchMatch {
+	matches := make([]SearchMatch, 0)
+
+	for idx := 0; idx+len(term.words) <= len(words); idx++ {
+		found := true
+		for offset, want := range term.words {
+			got := words[idx+offset].text
+			last := offset == len(term.words)-1
+			if got != want && !(last && term.prefix && strings.HasPrefix(got, want)) {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			end := words[idx+len(term.words)-1].end
+			matches = append(matches, SearchMatch{Start: words[idx].start, End: end})
+		}
+	}
+
+	return matches
+}
+
+// scoreTodo matches a Todo against every term. It returns false if any term is missing, and
+// otherwise the number of matches, boosted by the Todo's priority, and the matches in order.
+func scoreTodo(todo *Todo, terms []searchTerm) (float64, []SearchMatch, bool) {
+	words := searchWords(todo.Description)
+	matches := make([]SearchMatch, 0)
+
+	for _, term := range terms {
+		found := term.find(words)
+		if len(found) == 0 {
+			return 0, nil, false
+		}
+		matches = append(matches, found...)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+
+	score := float64(len(matches))
+	if todo.Priority > 0 && todo.Priority <= len(letters) {
+		score += searchPriorityWeight * float64(len(letters)-todo.Priority+1) / float64(len(letters))
+	}
+
+	return score, matches, true
+}
+
+// Search finds pending, completed and archived todos whose descriptions contain every word of a
+// search, ignoring case. Words in quotes must appear together as a phrase, and a word ending in *
+// matches any word starting with it. Results are ranked by how often the words appear and by
+// priority, with pending todos first among equals.
+func (tm *TodoManager) Search(search string) ([]SearchResult, error) {
+	results := make([]SearchResult, 0)
+
+	terms, err := parseSearch(search)
+	if err != nil {
+		return results, err
+	}
+
+	sources := []Storage{tm.Storage}
+	if tm.ArchiveStorage != nil {
+		sources = append(sources, tm.ArchiveStorage)
+	}
+
+	for idx, storage := range sources {
+		items, err := storage.List()
+		if err != nil {
+			return results, err
+		}
+
+		for _, todo := range items {
+			score, matches, ok := scoreTodo(todo, terms)
+			if !ok {
+				continue
+			}
+
+			results = append(results, SearchResult{
+				Todo:     todo,
+				Archived: idx > 0,
+				Score:    score,
+				Matches:  matches,
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Archived != b.Archived {
+			return !a.Archived
+		}
+		if a.Todo.Complete != b.Todo.Complete {
+			return !a.Todo.Complete
+		}
+		return a.Todo.TodoID < b.Todo.TodoID
+	})
+
+	return results, nil
+}
+
+// Highlight returns the todo.txt line of the result with before and after around every match
+func (me SearchResult) Highlight(before string, after string) string {
+	line := me.Todo.String()
+
+	// the description is always the end of the line, apart from trailing whitespace
+	offset := strings.LastIndex(line, me.Todo.Description)
+	if offset == -1 {
+		return line
+	}
+
+	var highlighted strings.Builder
+	last := 0
+	for _, match := range me.Matches {
+		start, end := offset+match.Start, offset+match.End
+		if start < last || end > len(line) {
+			continue
+		}
+		highlighted.WriteString(line[last:start])
+		highlighted.WriteString(before)
+		highlighted.WriteString(line[start:end])
+		highlighted.WriteString(after)
+		last = end
+	}
+	highlighted.WriteString(line[last:])
+
+	return highlighted.String()
+}
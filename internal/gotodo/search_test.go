@@ -0,0 +1,76 @@
+package gotodo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearch(t *testing.T) {
+	terms, err := parseSearch(`Invoice "that THING" acc*`)
+	assert.NoError(t, err)
+	assert.Equal(t, []searchTerm{
+		{words: []string{"invoice"}},
+		{words: []string{"that", "thing"}},
+		{words: []string{"acc"}, prefix: true},
+	}, terms)
+
+	_, err = parseSearch(" +* ")
+	assert.Equal(t, errEmptySearch, err)
+	_, err = parseSearch(`"unfinished`)
+	assert.Error(t, err)
+}
+
+func TestSearchWords(t *testing.T) {
+	assert.Equal(t, []token{
+		{text: "pay", start: 0, end: 3},
+		{text: "invoice", start: 5, end: 12},
+		{text: "42", start: 14, end: 16},
+	}, searchWords("Pay +Invoice #42"))
+}
+
+func TestSearch(t *testing.T) {
+	todoManager := getTestArchiveManager(t)
+	assert.NoError(t, todoManager.Update(1, "(B) 2020-04-28 Write unit tests for the parser @codehealth +gotodo"))
+	_, err := todoManager.Archive()
+	assert.NoError(t, err)
+	_, err = todoManager.Add("Parser test, parser docs and parser fixes")
+	assert.NoError(t, err)
+	_, err = todoManager.Add("x 2020-05-01 Unit test the parsers")
+	assert.NoError(t, err)
+
+	results, err := todoManager.Search("PARSER")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(results))
+
+	// ranked by matches, then priority, then pending before completed and archived
+	assert.Equal(t, "Parser test, parser docs and parser fixes", results[0].Todo.Description)
+	assert.Equal(t, 3.0, results[0].Score)
+	assert.Equal(t, "Write unit tests for the parser @codehealth +gotodo", results[1].Todo.Description)
+	assert.Equal(t, false, results[1].Archived)
+	assert.Equal(t, "Add parser test +gotodo due:2020-05-01", results[2].Todo.Description)
+	assert.Equal(t, true, results[2].Archived)
+
+	// phrases match words in order and prefixes match the start of words
+	results, err = todoManager.Search(`"unit test" pars*`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "x 2020-05-01 Unit test the parsers", results[0].Todo.String())
+
+	results, err = todoManager.Search("missing")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(results))
+}
+
+func TestSearchHighlight(t *testing.T) {
+	todo := FromString("(A)  2020-04-28 Pay the invoice, then file the Invoice")
+	terms, err := parseSearch("invoice")
+	assert.NoError(t, err)
+	_, matches, ok := scoreTodo(todo, terms)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, []SearchMatch{{Start: 8, End: 15}, {Start: 31, End: 38}}, matches)
+
+	result := SearchResult{Todo: todo, Matches: matches}
+
+	assert.Equal(t, "(A)  2020-04-28 Pay the [invoice], then file the [Invoice]", result.Highlight("[", "]"))
+}
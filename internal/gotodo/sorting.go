@@ -91,3 +91,60 @@ func (s ByPriority) Less(i, j int) bool {
 
 	return s[i].Priority < s[j].Priority
 }
+
+// ByEffectivePriority provides sorting by a computed priority, such as
+// TodoManager.EffectivePriority. Among todos with the same priority, those Overdue reports go
+// first, then the rest are ordered by DueDate. Overdue may be nil.
+type ByEffectivePriority struct {
+	Todos    TodoList
+	Priority func(*Todo) int
+	Overdue  func(*Todo) bool
+}
+
+// Len returns length of the slice
+func (s ByEffectivePriority) Len() int {
+	return len(s.Todos)
+}
+
+// Swap inverts positions of two elements
+func (s ByEffectivePriority) Swap(i, j int) {
+	s.Todos[i], s.Todos[j] = s.Todos[j], s.Todos[i]
+}
+
+// Less compares two elements by computed priority, then by whether they are overdue, then by
+// DueDate
+func (s ByEffectivePriority) Less(i, j int) bool {
+	p1 := s.Priority(s.Todos[i])
+	p2 := s.Priority(s.Todos[j])
+
+	// 0 priority means the todo is not prioritized.
+	// Consider 0 priority higher than any other value
+	if p1 == 0 && p2 > 0 {
+		return false
+	} else if p1 > 0 && p2 == 0 {
+		return true
+	} else if p1 != p2 {
+		return p1 < p2
+	}
+
+	// Overdue todos are a tier of their own above todos merely escalated to the same priority
+	if s.Overdue != nil {
+		o1 := s.Overdue(s.Todos[i])
+		o2 := s.Overdue(s.Todos[j])
+		if o1 != o2 {
+			return o1
+		}
+	}
+
+	t1 := s.Todos[i].DueDate
+	t2 := s.Todos[j].DueDate
+
+	// Among equal priorities, todos that are due first go first
+	if t1.Valid != t2.Valid {
+		return t1.Valid
+	} else if t1.Valid && !t1.Time.Equal(t2.Time) {
+		return t1.Time.Before(t2.Time)
+	}
+
+	return s.Todos[i].TodoID < s.Todos[j].TodoID
+}
@@ -61,3 +61,55 @@ func TestSortByPriority(t *testing.T) {
 	assert.Equal(t, "Index 3", todos[2].Description)
 	assert.Equal(t, "Index 2", todos[3].Description)
 }
+
+func TestSortByEffectivePriority(t *testing.T) {
+	now := time.Now()
+
+	todos := TodoList{
+		&Todo{TodoID: 1, Description: "Index 0", Priority: 1, DueDate: ValidTime(now)},
+		&Todo{TodoID: 2, Description: "Index 1", Priority: 1},
+		&Todo{TodoID: 3, Description: "Index 2", Priority: 0},
+		&Todo{TodoID: 4, Description: "Index 3", Priority: 1, DueDate: ValidTime(now.Add(-100))},
+		&Todo{TodoID: 5, Description: "Index 4", Priority: 2},
+	}
+
+	// Promote everything with a due date to priority A
+	priority := func(todo *Todo) int {
+		if todo.DueDate.Valid {
+			return 1
+		}
+		return todo.Priority
+	}
+
+	sort.Sort(ByEffectivePriority{Todos: todos, Priority: priority})
+	assert.Equal(t, len(todos), 5)
+	assert.Equal(t, "Index 3", todos[0].Description)
+	assert.Equal(t, "Index 0", todos[1].Description)
+	assert.Equal(t, "Index 1", todos[2].Description)
+	assert.Equal(t, "Index 4", todos[3].Description)
+	assert.Equal(t, "Index 2", todos[4].Description)
+}
+
+func TestSortByEffectivePriorityOverdue(t *testing.T) {
+	now := time.Now()
+
+	todos := TodoList{
+		&Todo{TodoID: 1, Description: "Index 0", Priority: 1, DueDate: ValidTime(now.Add(-100))},
+		&Todo{TodoID: 2, Description: "Index 1", Priority: 1, DueDate: ValidTime(now)},
+		&Todo{TodoID: 3, Description: "Index 2", Priority: 2, DueDate: ValidTime(now)},
+	}
+
+	priority := func(todo *Todo) int {
+		return todo.Priority
+	}
+
+	// Overdue ranks above an earlier due date, but never above a better priority
+	overdue := func(todo *Todo) bool {
+		return todo.TodoID != 1
+	}
+
+	sort.Sort(ByEffectivePriority{Todos: todos, Priority: priority, Overdue: overdue})
+	assert.Equal(t, "Index 1", todos[0].Description)
+	assert.Equal(t, "Index 0", todos[1].Description)
+	assert.Equal(t, "Index 2", todos[2].Description)
+}
@@ -2,12 +2,17 @@ package gotodo
 
 import (
 	"errors"
-	"strconv"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/boltdb/bolt"
 	"github.com/mitchellh/go-homedir"
 )
 
+// ErrNotFound is returned by Storage implementations when a todo ID does not exist
+var ErrNotFound = errors.New("Todo ID does not exist")
+
 // Storage provides an interface for various todo storage mechanisms (in-memory, filesystem)
 type Storage interface {
 	Create(todo *Todo) error
@@ -17,14 +22,47 @@ type Storage interface {
 	Delete(todoID int) error
 }
 
-// BoltStorage implements Storage, saving items to a file in the filesystem
+// Restorer is implemented by Storage that can recreate a deleted Todo under its original TodoID
+type Restorer interface {
+	Restore(todo *Todo) error
+}
+
+// Batcher is implemented by Storage that can apply several changes at once. fn receives a
+// Storage whose changes are saved together if fn succeeds and discarded if it returns an error.
+type Batcher interface {
+	Batch(fn func(tx Storage) error) error
+}
+
+// errExists is returned when restoring a Todo whose ID is in use
+var errExists = errors.New("Todo ID already exists")
+
+// BoltStorage implements Storage, saving items to a bucket in a Bolt database. The database is
+// opened on first use and stays open until Close. Path defaults to ~/.gotodo.db.
 type BoltStorage struct {
 	Bucket []byte
+	Path   string
+	db     *bolt.DB
 }
 
 // TodoDBFile is the name of the todo file in the user's home directory
 const todoDBFile = ".gotodo.db"
 
+// boltOpenTimeout is how long to wait for another process to release the database
+const boltOpenTimeout = 5 * time.Second
+
+// boltHandle is a database shared by every BoltStorage open on the same file. Bolt locks the file
+// while it is open, so opening it a second time in the same process would block forever.
+type boltHandle struct {
+	db   *bolt.DB
+	refs int
+}
+
+// boltHandles holds the open databases by path
+var boltHandles = struct {
+	sync.Mutex
+	byPath map[string]*boltHandle
+}{byPath: make(map[string]*boltHandle)}
+
 // getHomeDir returns the user's home directory
 func getHomeDir() (string, error) {
 	relPath, err := homedir.Dir()
@@ -40,117 +78,165 @@ func getHomeDir() (string, error) {
 	return absPath, nil
 }
 
-// getDB returns an instance of *bolt.DB
-func (me *BoltStorage) getDB() (*bolt.DB, error) {
+// getPath returns the absolute path of the database file
+func (me *BoltStorage) getPath() (string, error) {
+	if me.Path != "" {
+		return homedir.Expand(me.Path)
+	}
+
 	absPath, err := getHomeDir()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	dbPath := absPath + "/" + todoDBFile
 
-	db, err := bolt.Open(dbPath, 0600, nil)
+	return absPath + "/" + todoDBFile, nil
+}
+
+// getDB returns the open *bolt.DB, opening it and creating or migrating the bucket on first use
+func (me *BoltStorage) getDB() (*bolt.DB, error) {
+	if me.db != nil {
+		return me.db, nil
+	}
+
+	dbPath, err := me.getPath()
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(me.Bucket)
-		if err != nil {
-			return err
+	boltHandles.Lock()
+	defer boltHandles.Unlock()
+
+	handle, ok := boltHandles.byPath[dbPath]
+	if !ok {
+		db, err := bolt.Open(dbPath, 0600, &bolt.Options{Timeout: boltOpenTimeout})
+		if err == bolt.ErrTimeout {
+			return nil, fmt.Errorf("Timed out opening %s, it is in use by another process", dbPath)
+		} else if err != nil {
+			return nil, err
 		}
-		return nil
+
+		handle = &boltHandle{db: db}
+		boltHandles.byPath[dbPath] = handle
+	}
+
+	err = handle.db.Update(func(tx *bolt.Tx) error {
+		_, err := openBoltTx(tx, me.Bucket)
+		return err
 	})
 	if err != nil {
+		if handle.refs == 0 {
+			handle.db.Close()
+			delete(boltHandles.byPath, dbPath)
+		}
 		return nil, err
 	}
 
-	return db, nil
+	handle.refs++
+	me.db = handle.db
+
+	return me.db, nil
 }
 
-// checkKey checks for the existence of a key in Bolt.
-func (me *BoltStorage) checkKey(key string, db *bolt.DB) error {
-	return db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(me.Bucket)
+// Close releases the database. It is closed once every BoltStorage using it has been closed.
+func (me *BoltStorage) Close() error {
+	if me.db == nil {
+		return nil
+	}
 
-		v := b.Get([]byte(key))
-		if v == nil {
-			return errors.New("Todo ID does not exist")
-		}
+	boltHandles.Lock()
+	defer boltHandles.Unlock()
+
+	db := me.db
+	me.db = nil
+
+	dbPath := db.Path()
+	handle, ok := boltHandles.byPath[dbPath]
+	if !ok || handle.db != db {
+		return db.Close()
+	}
 
+	handle.refs--
+	if handle.refs > 0 {
 		return nil
+	}
+
+	delete(boltHandles.byPath, dbPath)
+	return db.Close()
+}
+
+// view runs fn in a read-only transaction
+func (me *BoltStorage) view(fn func(tx *boltTx) error) error {
+	db, err := me.getDB()
+	if err != nil {
+		return err
+	}
+
+	return db.View(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx, bucket: me.Bucket})
 	})
 }
 
-// Create inserts a new *Todo
-func (me *BoltStorage) Create(todo *Todo) error {
+// update runs fn in a read-write transaction, which is committed if fn succeeds
+func (me *BoltStorage) update(fn func(tx *boltTx) error) error {
 	db, err := me.getDB()
 	if err != nil {
 		return err
 	}
-	defer db.Close()
 
 	return db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(me.Bucket)
-		id, _ := b.NextSequence()
-		todo.TodoID = int(id)
-		key := strconv.Itoa(todo.TodoID)
-		value := todo.String()
-		return b.Put([]byte(key), []byte(value))
+		return fn(&boltTx{tx: tx, bucket: me.Bucket})
 	})
 }
 
-// Get retrieves the *Todo identified by todoID
-func (me *BoltStorage) Get(todoID int) (*Todo, error) {
-	var todo *Todo
-
+// viewDB runs fn in a read-only transaction of the whole database
+func (me *BoltStorage) viewDB(fn func(tx *bolt.Tx) error) error {
 	db, err := me.getDB()
 	if err != nil {
-		return todo, err
+		return err
 	}
-	defer db.Close()
 
-	key := strconv.Itoa(todoID)
-	err = me.checkKey(key, db)
+	return db.View(fn)
+}
+
+// updateDB runs fn in a read-write transaction of the whole database, which is committed if fn
+// succeeds
+func (me *BoltStorage) updateDB(fn func(tx *bolt.Tx) error) error {
+	db, err := me.getDB()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(me.Bucket)
-		v := b.Get([]byte(key))
-		todo = FromString(string(v))
-		todo.TodoID = todoID
-		return nil
+	return db.Update(fn)
+}
+
+// Create inserts a new *Todo
+func (me *BoltStorage) Create(todo *Todo) error {
+	return me.update(func(tx *boltTx) error {
+		return tx.Create(todo)
 	})
+}
 
-	if err != nil {
-		return todo, err
-	}
+// Get retrieves the *Todo identified by todoID
+func (me *BoltStorage) Get(todoID int) (*Todo, error) {
+	var todo *Todo
 
-	return todo, nil
+	err := me.view(func(tx *boltTx) error {
+		var err error
+		todo, err = tx.Get(todoID)
+		return err
+	})
+
+	return todo, err
 }
 
 // List reads all Todos
 func (me *BoltStorage) List() (TodoList, error) {
 	items := make(TodoList, 0)
 
-	db, err := me.getDB()
-	if err != nil {
-		return items, err
-	}
-	defer db.Close()
-
-	err = db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(me.Bucket)
-		c := b.Cursor()
-
-		for k, v := c.First(); k != nil; k, v = c.Next() {
-			todo := FromString(string(v))
-			todo.TodoID, _ = strconv.Atoi(string(k))
-			items = append(items, todo)
-		}
-
-		return nil
+	err := me.view(func(tx *boltTx) error {
+		var err error
+		items, err = tx.List()
+		return err
 	})
 
 	return items, err
@@ -158,45 +244,210 @@ func (me *BoltStorage) List() (TodoList, error) {
 
 // Update modifies the *Todo identified by todoID
 func (me *BoltStorage) Update(todoID int, todo *Todo) error {
+	return me.update(func(tx *boltTx) error {
+		return tx.Update(todoID, todo)
+	})
+}
+
+// Delete removes the *Todo identified by todoID
+func (me *BoltStorage) Delete(todoID int) error {
+	return me.update(func(tx *boltTx) error {
+		return tx.Delete(todoID)
+	})
+}
+
+// Restore recreates a deleted *Todo under its original TodoID
+func (me *BoltStorage) Restore(todo *Todo) error {
+	return me.update(func(tx *boltTx) error {
+		return tx.Restore(todo)
+	})
+}
+
+// Batch runs fn in a single Bolt transaction, which is committed if fn succeeds and rolled back
+// if it returns an error
+func (me *BoltStorage) Batch(fn func(tx Storage) error) error {
+	return me.update(func(tx *boltTx) error {
+		return fn(tx)
+	})
+}
+
+// batchWith runs fn in a single transaction covering both this bucket and the bucket of other.
+// If other lives in a different database, its transaction is nested inside this one.
+func (me *BoltStorage) batchWith(other *BoltStorage, fn func(tx Storage, otherTx Storage) error) error {
 	db, err := me.getDB()
 	if err != nil {
 		return err
 	}
-	defer db.Close()
 
-	key := strconv.Itoa(todoID)
-	value := todo.String()
-
-	// make sure the key exists before working with it
-	err = me.checkKey(key, db)
+	otherDB, err := other.getDB()
 	if err != nil {
 		return err
 	}
 
+	if otherDB != db {
+		return me.update(func(tx *boltTx) error {
+			return other.update(func(otherTx *boltTx) error {
+				return fn(tx, otherTx)
+			})
+		})
+	}
+
 	return db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(me.Bucket)
-		return b.Put([]byte(key), []byte(value))
+		return fn(&boltTx{tx: tx, bucket: me.Bucket}, &boltTx{tx: tx, bucket: other.Bucket})
 	})
 }
 
-// Delete removes the *Todo identified by todoID
-func (me *BoltStorage) Delete(todoID int) error {
-	db, err := me.getDB()
+// boltTx implements Storage on a bucket within an open Bolt transaction
+type boltTx struct {
+	tx     *bolt.Tx
+	bucket []byte
+}
+
+// openBoltTx returns a boltTx for the bucket called name within tx, creating or migrating the
+// bucket first
+func openBoltTx(tx *bolt.Tx, name []byte) (*boltTx, error) {
+	b, err := tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateBolt(b); err != nil {
+		return nil, err
+	}
+
+	return &boltTx{tx: tx, bucket: name}, nil
+}
+
+// put saves the *Todo identified by todoID
+func (me *boltTx) put(b *bolt.Bucket, todoID int, todo *Todo) error {
+	value, err := encodeBoltRecord(todoID, todo, time.Now())
 	if err != nil {
 		return err
 	}
-	defer db.Close()
 
-	key := strconv.Itoa(todoID)
+	return b.Put(boltKey(todoID), value)
+}
 
-	// make sure the key exists before working with it
-	err = me.checkKey(key, db)
+// decode converts a saved record back into the *Todo identified by todoID
+func (me *boltTx) decode(todoID int, value []byte) (*Todo, error) {
+	todo, err := decodeBoltRecord(value)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read Todo ID %d: %s", todoID, err)
+	}
+	todo.TodoID = todoID
+
+	return todo, nil
+}
+
+// Create inserts a new *Todo
+func (me *boltTx) Create(todo *Todo) error {
+	b := me.tx.Bucket(me.bucket)
+	id, err := b.NextSequence()
 	if err != nil {
 		return err
 	}
 
-	return db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(me.Bucket)
-		return b.Delete([]byte(key))
+	todo.TodoID = int(id)
+	return me.put(b, todo.TodoID, todo)
+}
+
+// Get retrieves the *Todo identified by todoID
+func (me *boltTx) Get(todoID int) (*Todo, error) {
+	if todoID < 1 {
+		return nil, ErrNotFound
+	}
+
+	v := me.tx.Bucket(me.bucket).Get(boltKey(todoID))
+	if v == nil {
+		return nil, ErrNotFound
+	}
+
+	return me.decode(todoID, v)
+}
+
+// List reads all Todos, ordered by ID
+func (me *boltTx) List() (TodoList, error) {
+	items := make(TodoList, 0)
+
+	c := me.tx.Bucket(me.bucket).Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		todoID := boltTodoID(k)
+		if todoID == 0 {
+			continue
+		}
+
+		todo, err := me.decode(todoID, v)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, todo)
+	}
+
+	return items, nil
+}
+
+// Update modifies the *Todo identified by todoID
+func (me *boltTx) Update(todoID int, todo *Todo) error {
+	b := me.tx.Bucket(me.bucket)
+
+	// make sure the key exists before working with it
+	if todoID < 1 || b.Get(boltKey(todoID)) == nil {
+		return ErrNotFound
+	}
+
+	return me.put(b, todoID, todo)
+}
+
+// Delete removes the *Todo identified by todoID
+func (me *boltTx) Delete(todoID int) error {
+	b := me.tx.Bucket(me.bucket)
+	key := boltKey(todoID)
+
+	// make sure the key exists before working with it
+	if todoID < 1 || b.Get(key) == nil {
+		return ErrNotFound
+	}
+
+	return b.Delete(key)
+}
+
+// Restore recreates a deleted *Todo under its original TodoID. Restoring an ID the sequence
+// hasn't reached yet moves the sequence up to it.
+func (me *boltTx) Restore(todo *Todo) error {
+	b := me.tx.Bucket(me.bucket)
+
+	// make sure the key is free before working with it
+	if todo.TodoID < 1 {
+		return ErrNotFound
+	} else if b.Get(boltKey(todo.TodoID)) != nil {
+		return errExists
+	}
+
+	// move the sequence past the ID so a new Todo never overwrites it
+	if uint64(todo.TodoID) > b.Sequence() {
+		if err := b.SetSequence(uint64(todo.TodoID)); err != nil {
+			return err
+		}
+	}
+
+	return me.put(b, todo.TodoID, todo)
+}
+
+// Buckets returns the names of every bucket in the database, in alphabetical order
+func (me *BoltStorage) Buckets() ([]string, error) {
+	names := make([]string, 0)
+
+	db, err := me.getDB()
+	if err != nil {
+		return names, err
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			names = append(names, string(name))
+			return nil
+		})
 	})
+
+	return names, err
 }
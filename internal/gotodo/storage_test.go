@@ -1,5 +1,15 @@
 package gotodo
 
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/assert"
+)
+
 type TestStorage struct {
 	items TodoList
 }
@@ -31,3 +41,200 @@ func (me *TestStorage) Delete(todoID int) error {
 	me.items = todos
 	return nil
 }
+
+func getTestBoltStorage(t *testing.T, bucket string) *BoltStorage {
+	dir, err := ioutil.TempDir("", "gotodo")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	storage := &BoltStorage{Bucket: []byte(bucket), Path: filepath.Join(dir, "gotodo.db")}
+	t.Cleanup(func() { storage.Close() })
+
+	return storage
+}
+
+func TestBoltStorage(t *testing.T) {
+	storage := getTestBoltStorage(t, "Todos")
+
+	todo := FromString("(B) 2020-04-28 Work on unit tests")
+	assert.NoError(t, storage.Create(todo))
+	assert.Equal(t, 1, todo.TodoID)
+	assert.NoError(t, storage.Create(FromString("Add parser test")))
+
+	todo, err := storage.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Add parser test", todo.Description)
+
+	assert.NoError(t, storage.Update(2, FromString("(A) Add parser test")))
+	assert.Equal(t, ErrNotFound, storage.Update(3, FromString("Missing todo")))
+
+	assert.NoError(t, storage.Delete(1))
+	assert.Equal(t, ErrNotFound, storage.Delete(1))
+	_, err = storage.Get(1)
+	assert.Equal(t, ErrNotFound, err)
+
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "(A) Add parser test", items[0].String())
+
+	todo = FromString("Restored todo")
+	todo.TodoID = 1
+	assert.NoError(t, storage.Restore(todo))
+	assert.Equal(t, errExists, storage.Restore(todo))
+}
+
+func TestBoltStorageBatch(t *testing.T) {
+	storage := getTestBoltStorage(t, "Todos")
+	assert.NoError(t, storage.Create(FromString("First todo")))
+
+	err := storage.Batch(func(tx Storage) error {
+		assert.NoError(t, tx.Create(FromString("Second todo")))
+		return tx.Delete(5)
+	})
+	assert.Equal(t, ErrNotFound, err)
+
+	// the failed batch is rolled back
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(items))
+}
+
+func TestBoltStorageSharedDB(t *testing.T) {
+	storage := getTestBoltStorage(t, "Todos")
+	archive := &BoltStorage{Bucket: []byte("TodosArchive"), Path: storage.Path}
+
+	assert.NoError(t, storage.Create(FromString("First todo")))
+	assert.NoError(t, archive.Create(FromString("x Archived todo")))
+
+	// both buckets are written in one transaction of the same database
+	err := storage.batchWith(archive, func(tx Storage, archiveTx Storage) error {
+		todo, err := tx.Get(1)
+		assert.NoError(t, err)
+		assert.NoError(t, archiveTx.Create(todo))
+		return tx.Delete(1)
+	})
+	assert.NoError(t, err)
+
+	items, err := archive.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+
+	// the database stays open until every storage using it is closed
+	assert.NoError(t, archive.Close())
+	items, err = storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+	assert.NoError(t, storage.Close())
+	assert.NoError(t, storage.Close())
+}
+
+// writeTestBoltBucket fills a bucket directly, bypassing BoltStorage
+func writeTestBoltBucket(t *testing.T, storage *BoltStorage, sequence uint64, values map[string]string) {
+	db, err := bolt.Open(storage.Path, 0600, nil)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(storage.Bucket)
+		if err != nil {
+			return err
+		}
+		for key, value := range values {
+			if err := b.Put([]byte(key), []byte(value)); err != nil {
+				return err
+			}
+		}
+		return b.SetSequence(sequence)
+	})
+	assert.NoError(t, err)
+}
+
+func TestBoltStorageRecords(t *testing.T) {
+	storage := getTestBoltStorage(t, "Todos")
+
+	todoStr := "(A)  2020-04-28 Work on   unit tests +gotodo @codehealth due:2020-05-01 t:2020-04-30 rec:1w"
+	assert.NoError(t, storage.Create(FromString(todoStr)))
+
+	// the line is kept exactly as written, with every field stored
+	todo, err := storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, todoStr, todo.String())
+	assert.Equal(t, FromString(todoStr).Description, todo.Description)
+	assert.Equal(t, "2020-05-01", todo.DueDate.Display())
+	assert.Equal(t, "2020-04-30", todo.ThresholdDate.Display())
+	assert.Equal(t, []string{"gotodo"}, todo.Projects.Sorted())
+	assert.Equal(t, "1w", todo.Attributes["rec"])
+
+	// changing a field rewrites the line
+	todo.Priority = 2
+	assert.NoError(t, storage.Update(1, todo))
+	todo, err = storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, todo.Priority)
+	assert.Equal(t, "(B) 2020-04-28 Work on   unit tests +gotodo @codehealth due:2020-05-01 t:2020-04-30 rec:1w", todo.String())
+}
+
+func TestBoltStorageMutators(t *testing.T) {
+	storage := getTestBoltStorage(t, "Todos")
+	todoManager := NewTodoManager(WithBoltStorage("Todos"))
+	todoManager.Storage = storage
+
+	_, err := todoManager.Add("Call mom")
+	assert.NoError(t, err)
+
+	// the fields Bolt stores are parsed again from the changed description
+	assert.NoError(t, todoManager.Append(1, "due:2020-01-05 +family @home"))
+	assert.NoError(t, todoManager.Prepend(1, "Really"))
+	assert.NoError(t, todoManager.AddAttribute(1, "t:2020-01-01"))
+	assert.NoError(t, todoManager.AddProject(1, "phone"))
+	assert.NoError(t, todoManager.AddContext(1, "evening"))
+
+	todo, err := storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Really Call mom due:2020-01-05 +family @home t:2020-01-01 +phone @evening", todo.String())
+	assert.Equal(t, "2020-01-05", todo.DueDate.Display())
+	assert.Equal(t, "2020-01-01", todo.ThresholdDate.Display())
+	assert.Equal(t, []string{"family", "phone"}, todo.Projects.Sorted())
+	assert.Equal(t, []string{"evening", "home"}, todo.Contexts.Sorted())
+	assert.Equal(t, Attributes{"due": "2020-01-05", "t": "2020-01-01"}, todo.Attributes)
+}
+
+func TestBoltStorageMigrate(t *testing.T) {
+	storage := getTestBoltStorage(t, "Todos")
+	writeTestBoltBucket(t, storage, 12, map[string]string{
+		"2":  "(B) 2020-04-28 Work on unit tests +gotodo",
+		"10": "x 2020-04-29 Add parser test",
+		"11": "Write  docs   due:2020-05-01",
+	})
+
+	items, err := storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(items))
+	assert.Equal(t, 2, items[0].TodoID)
+	assert.Equal(t, "(B) 2020-04-28 Work on unit tests +gotodo", items[0].String())
+	assert.Equal(t, 10, items[1].TodoID)
+	assert.Equal(t, true, items[1].Complete)
+	assert.Equal(t, 11, items[2].TodoID)
+	assert.Equal(t, "Write  docs   due:2020-05-01", items[2].String())
+	assert.Equal(t, "2020-05-01", items[2].DueDate.Display())
+
+	// the sequence carries over, so new todos don't reuse IDs
+	todo := FromString("New todo")
+	assert.NoError(t, storage.Create(todo))
+	assert.Equal(t, 13, todo.TodoID)
+
+	// a migrated bucket isn't migrated again
+	assert.NoError(t, storage.Close())
+	items, err = storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(items))
+}
+
+func TestBoltStorageNewerSchema(t *testing.T) {
+	storage := getTestBoltStorage(t, "Todos")
+	writeTestBoltBucket(t, storage, 0, map[string]string{"schema": "99"})
+
+	_, err := storage.List()
+	assert.EqualError(t, err, "Bucket uses schema version 99, which needs a newer version of gotodo")
+}
@@ -0,0 +1,236 @@
+// Package storagetest is a conformance suite for gotodo.Storage implementations. Every storage
+// must pass it for the TodoManager, journal and undo to work on top of it.
+package storagetest
+
+import (
+	"testing"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/stretchr/testify/assert"
+)
+
+// Factory returns a new, empty Storage for a single test
+type Factory func(t *testing.T) gotodo.Storage
+
+// Run checks that the storages returned by newStorage create, get, list, update and delete todos
+// with the semantics the TodoManager relies on: IDs start at 1 and only ever increase, List is
+// ordered by ID, missing IDs are gotodo.ErrNotFound, and a *Todo is only saved by Create or
+// Update. Storages that implement gotodo.Restorer or gotodo.Batcher are checked for those too.
+func Run(t *testing.T, newStorage Factory) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newStorage(t)) })
+	t.Run("Get", func(t *testing.T) { testGet(t, newStorage(t)) })
+	t.Run("List", func(t *testing.T) { testList(t, newStorage(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newStorage(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newStorage(t)) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, newStorage(t)) })
+	t.Run("Monotonic", func(t *testing.T) { testMonotonic(t, newStorage(t)) })
+	t.Run("Restore", func(t *testing.T) { testRestore(t, newStorage(t)) })
+	t.Run("Batch", func(t *testing.T) { testBatch(t, newStorage(t)) })
+}
+
+// create adds todos to storage and returns their IDs
+func create(t *testing.T, storage gotodo.Storage, todoStrs ...string) []int {
+	todoIDs := make([]int, 0, len(todoStrs))
+	for _, todoStr := range todoStrs {
+		todo := gotodo.FromString(todoStr)
+		if !assert.NoError(t, storage.Create(todo)) {
+			t.FailNow()
+		}
+		todoIDs = append(todoIDs, todo.TodoID)
+	}
+
+	return todoIDs
+}
+
+// listStrings returns the IDs and todo.txt strings of every todo in storage
+func listStrings(t *testing.T, storage gotodo.Storage) ([]int, []string) {
+	items, err := storage.List()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	todoIDs := make([]int, 0, len(items))
+	todoStrs := make([]string, 0, len(items))
+	for _, todo := range items {
+		todoIDs = append(todoIDs, todo.TodoID)
+		todoStrs = append(todoStrs, todo.String())
+	}
+
+	return todoIDs, todoStrs
+}
+
+func testCreate(t *testing.T, storage gotodo.Storage) {
+	todo := gotodo.FromString("(B) 2020-04-28 Work on unit tests +gotodo")
+	assert.NoError(t, storage.Create(todo))
+	assert.Equal(t, 1, todo.TodoID)
+
+	todoIDs := create(t, storage, "Add parser test", "x 2020-04-29 Write docs")
+	assert.Equal(t, []int{2, 3}, todoIDs)
+}
+
+func testGet(t *testing.T, storage gotodo.Storage) {
+	create(t, storage, "(A) 2020-04-28 Call mom @phone +family due:2020-05-01", "Add parser test")
+
+	todo, err := storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, todo.TodoID)
+	assert.Equal(t, "(A) 2020-04-28 Call mom @phone +family due:2020-05-01", todo.String())
+
+	// changing a *Todo doesn't change storage until it is updated
+	todo.Description = "Changed"
+	todo, err = storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "(A) 2020-04-28 Call mom @phone +family due:2020-05-01", todo.String())
+
+	todo, err = storage.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, todo.TodoID)
+	assert.Equal(t, "Add parser test", todo.Description)
+}
+
+func testList(t *testing.T, storage gotodo.Storage) {
+	todoIDs, todoStrs := listStrings(t, storage)
+	assert.Empty(t, todoIDs)
+	assert.Empty(t, todoStrs)
+
+	create(t, storage, "Third alphabetically", "First alphabetically", "(A) Second alphabetically")
+	assert.NoError(t, storage.Delete(2))
+	create(t, storage, "Another todo")
+
+	todoIDs, todoStrs = listStrings(t, storage)
+	assert.Equal(t, []int{1, 3, 4}, todoIDs)
+	assert.Equal(t, []string{"Third alphabetically", "(A) Second alphabetically", "Another todo"}, todoStrs)
+
+	// IDs are ordered as numbers, not strings
+	for i := 0; i < 8; i++ {
+		create(t, storage, "Filler todo")
+	}
+	todoIDs, _ = listStrings(t, storage)
+	assert.Equal(t, []int{1, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, todoIDs)
+}
+
+func testUpdate(t *testing.T, storage gotodo.Storage) {
+	create(t, storage, "Add parser test", "Write docs")
+
+	todo, err := storage.Get(1)
+	assert.NoError(t, err)
+	todo.Description = "Add storage test"
+	assert.NoError(t, storage.Update(1, todo))
+
+	todo, err = storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Add storage test", todo.String())
+
+	// the ID comes from the argument, not the *Todo
+	assert.NoError(t, storage.Update(2, gotodo.FromString("Write more docs")))
+	todoIDs, todoStrs := listStrings(t, storage)
+	assert.Equal(t, []int{1, 2}, todoIDs)
+	assert.Equal(t, []string{"Add storage test", "Write more docs"}, todoStrs)
+}
+
+func testDelete(t *testing.T, storage gotodo.Storage) {
+	create(t, storage, "Add parser test", "Write docs", "Ship it")
+
+	assert.NoError(t, storage.Delete(2))
+	_, err := storage.Get(2)
+	assert.Equal(t, gotodo.ErrNotFound, err)
+
+	// the other todos keep their IDs
+	todoIDs, todoStrs := listStrings(t, storage)
+	assert.Equal(t, []int{1, 3}, todoIDs)
+	assert.Equal(t, []string{"Add parser test", "Ship it"}, todoStrs)
+}
+
+func testNotFound(t *testing.T, storage gotodo.Storage) {
+	create(t, storage, "Add parser test")
+	assert.NoError(t, storage.Delete(1))
+
+	for _, todoID := range []int{-1, 0, 1, 2} {
+		_, err := storage.Get(todoID)
+		assert.Equal(t, gotodo.ErrNotFound, err, "Get(%d)", todoID)
+		assert.Equal(t, gotodo.ErrNotFound, storage.Update(todoID, gotodo.FromString("Missing todo")), "Update(%d)", todoID)
+		assert.Equal(t, gotodo.ErrNotFound, storage.Delete(todoID), "Delete(%d)", todoID)
+	}
+
+	// a failed update doesn't create the todo
+	todoIDs, _ := listStrings(t, storage)
+	assert.Empty(t, todoIDs)
+}
+
+func testMonotonic(t *testing.T, storage gotodo.Storage) {
+	last := 0
+	for _, todoStrs := range [][]string{{"Only todo"}, {"First todo", "Second todo"}, {"Third todo"}} {
+		todoIDs := create(t, storage, todoStrs...)
+		for _, todoID := range todoIDs {
+			assert.Greater(t, todoID, last, "IDs must only increase")
+			last = todoID
+		}
+
+		// deleting the newest todos, or every todo, never frees their IDs
+		for _, todoID := range todoIDs {
+			assert.NoError(t, storage.Delete(todoID))
+		}
+	}
+}
+
+func testRestore(t *testing.T, storage gotodo.Storage) {
+	restorer, ok := storage.(gotodo.Restorer)
+	if !ok {
+		t.Skip("Storage doesn't implement Restorer")
+	}
+
+	create(t, storage, "Add parser test", "Write docs")
+	todo, err := storage.Get(1)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.Delete(1))
+
+	assert.NoError(t, restorer.Restore(todo))
+	assert.Error(t, restorer.Restore(todo), "an ID in use can't be restored")
+
+	todoIDs, todoStrs := listStrings(t, storage)
+	assert.Equal(t, []int{1, 2}, todoIDs)
+	assert.Equal(t, []string{"Add parser test", "Write docs"}, todoStrs)
+
+	// a restored todo doesn't make a new todo reuse an ID
+	todoIDs = create(t, storage, "Ship it")
+	assert.Equal(t, []int{3}, todoIDs)
+
+	// neither does one restored past the newest ID
+	todo = gotodo.FromString("Restored from a backup")
+	todo.TodoID = 10
+	assert.NoError(t, restorer.Restore(todo))
+	todoIDs = create(t, storage, "Ship it again")
+	assert.Equal(t, []int{11}, todoIDs)
+}
+
+func testBatch(t *testing.T, storage gotodo.Storage) {
+	batcher, ok := storage.(gotodo.Batcher)
+	if !ok {
+		t.Skip("Storage doesn't implement Batcher")
+	}
+
+	create(t, storage, "First todo")
+
+	err := batcher.Batch(func(tx gotodo.Storage) error {
+		create(t, tx, "Second todo")
+		assert.NoError(t, tx.Update(1, gotodo.FromString("(A) First todo")))
+		return tx.Delete(5)
+	})
+	assert.Equal(t, gotodo.ErrNotFound, err)
+
+	// a failed batch is discarded
+	todoIDs, todoStrs := listStrings(t, storage)
+	assert.Equal(t, []int{1}, todoIDs)
+	assert.Equal(t, []string{"First todo"}, todoStrs)
+
+	err = batcher.Batch(func(tx gotodo.Storage) error {
+		todoIDs := create(t, tx, "Third todo")
+		assert.Greater(t, todoIDs[0], 1)
+		return tx.Delete(1)
+	})
+	assert.NoError(t, err)
+
+	todoIDs, todoStrs = listStrings(t, storage)
+	assert.Len(t, todoIDs, 1)
+	assert.Equal(t, []string{"Third todo"}, todoStrs)
+}
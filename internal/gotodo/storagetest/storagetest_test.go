@@ -0,0 +1,41 @@
+package storagetest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/stretchr/testify/assert"
+)
+
+// tempDir returns a directory removed when the test ends
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "gotodo")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+func TestMemoryStorage(t *testing.T) {
+	Run(t, func(t *testing.T) gotodo.Storage {
+		return &gotodo.MemoryStorage{}
+	})
+}
+
+func TestBoltStorage(t *testing.T) {
+	Run(t, func(t *testing.T) gotodo.Storage {
+		storage := &gotodo.BoltStorage{Bucket: []byte("Todos"), Path: filepath.Join(tempDir(t), "gotodo.db")}
+		t.Cleanup(func() { storage.Close() })
+
+		return storage
+	})
+}
+
+func TestFileStorage(t *testing.T) {
+	Run(t, func(t *testing.T) gotodo.Storage {
+		return &gotodo.FileStorage{Path: filepath.Join(tempDir(t), "todo.txt")}
+	})
+}
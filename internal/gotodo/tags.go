@@ -0,0 +1,232 @@
+package gotodo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TodoChange is a Todo before and after a change, as todo.txt strings
+type TodoChange struct {
+	TodoID int
+	Before string
+	After  string
+}
+
+// errNoDescription is returned when removing a tag would leave a Todo without a description
+var errNoDescription = errors.New("Todo would have no description left")
+
+// rewriteWords passes every word of text to fn and replaces it with the result, keeping the
+// whitespace between words. A word fn returns as "" is removed along with the whitespace before it.
+func rewriteWords(text string, fn func(word string) string) string {
+	var rewritten strings.Builder
+	last := 0
+	dropSpace := false
+
+	for _, tok := range tokenize(text) {
+		space := text[last:tok.start]
+		last = tok.end
+
+		word := fn(tok.text)
+		if word == "" {
+			// Without a word before it, drop the whitespace after it instead
+			if rewritten.Len() == 0 {
+				dropSpace = true
+			}
+			continue
+		}
+
+		if dropSpace {
+			space = ""
+			dropSpace = false
+		}
+		rewritten.WriteString(space)
+		rewritten.WriteString(word)
+	}
+
+	return rewritten.String()
+}
+
+// attributeKey returns the key of a key:value attribute word, or "" if the word isn't one
+func attributeKey(word string) string {
+	for key := range parseAttributes([]string{word}) {
+		return key
+	}
+
+	return ""
+}
+
+// checkTagName returns an error if name can't be used as a project, context or attribute key
+func checkTagName(kind string, name string) error {
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("Invalid %s name \"%s\"", kind, name)
+	}
+	if kind == "attribute" && strings.Contains(name, ":") {
+		return fmt.Errorf("Invalid attribute name \"%s\", it can't contain a colon", name)
+	}
+
+	return nil
+}
+
+// removeWords removes the words of a Todo's description matched by fn. It returns an error if
+// none match, or if the description would be left empty.
+func removeWords(todo *Todo, missing error, fn func(word string) bool) error {
+	description := rewriteWords(todo.Description, func(word string) string {
+		if fn(word) {
+			return ""
+		}
+		return word
+	})
+
+	if description == todo.Description {
+		return missing
+	} else if description == "" {
+		return errNoDescription
+	}
+
+	todo.setDescription(description)
+	return nil
+}
+
+// RemoveProject removes a project tag from a todo
+func (tm *TodoManager) RemoveProject(todoID int, project string) error {
+	return singleResult(tm.RemoveProjectAll([]int{todoID}, project))
+}
+
+// RemoveProjectAll removes a project tag from every Todo identified by todoIDs in a single batch
+func (tm *TodoManager) RemoveProjectAll(todoIDs []int, project string) ([]BulkResult, error) {
+	missing := fmt.Errorf("Todo isn't in project \"%s\"", project)
+
+	return tm.modifyAll("rmproject", todoIDs, func(todo *Todo) error {
+		return removeWords(todo, missing, func(word string) bool {
+			return word == "+"+project
+		})
+	})
+}
+
+// RemoveContext removes a context tag from a todo
+func (tm *TodoManager) RemoveContext(todoID int, context string) error {
+	return singleResult(tm.RemoveContextAll([]int{todoID}, context))
+}
+
+// RemoveContextAll removes a context tag from every Todo identified by todoIDs in a single batch
+func (tm *TodoManager) RemoveContextAll(todoIDs []int, context string) ([]BulkResult, error) {
+	missing := fmt.Errorf("Todo isn't in context \"%s\"", context)
+
+	return tm.modifyAll("rmcontext", todoIDs, func(todo *Todo) error {
+		return removeWords(todo, missing, func(word string) bool {
+			return word == "@"+context
+		})
+	})
+}
+
+// RemoveAttribute removes every key:value attribute with the given key from a todo
+func (tm *TodoManager) RemoveAttribute(todoID int, key string) error {
+	missing := fmt.Errorf("Todo has no attribute \"%s\"", key)
+
+	return tm.modify("rmattribute", todoID, func(todo *Todo) error {
+		return removeWords(todo, missing, func(word string) bool {
+			return attributeKey(word) == key
+		})
+	})
+}
+
+// RenameProject renames a project in every pending and completed Todo in a single batch, and
+// returns the changes. With dryRun, the changes are returned without being saved.
+func (tm *TodoManager) RenameProject(oldName string, newName string, dryRun bool) ([]TodoChange, error) {
+	return tm.renameTag("renameproject", "project", "+", oldName, newName, dryRun)
+}
+
+// RenameContext renames a context in every pending and completed Todo in a single batch, and
+// returns the changes. With dryRun, the changes are returned without being saved.
+func (tm *TodoManager) RenameContext(oldName string, newName string, dryRun bool) ([]TodoChange, error) {
+	return tm.renameTag("renamecontext", "context", "@", oldName, newName, dryRun)
+}
+
+// RenameAttribute renames an attribute key in every pending and completed Todo in a single batch,
+// keeping the values, and returns the changes. With dryRun, the changes are returned without being
+// saved.
+func (tm *TodoManager) RenameAttribute(oldKey string, newKey string, dryRun bool) ([]TodoChange, error) {
+	return tm.renameTag("renameattribute", "attribute", "", oldKey, newKey, dryRun)
+}
+
+// renameTag rewrites the projects, contexts or attributes named oldName in every Todo. Projects
+// and contexts start with prefix, while attributes have none. A todo that already has the new
+// project or context keeps a single one of it, while one that already has the new attribute can't
+// be renamed.
+func (tm *TodoManager) renameTag(name string, kind string, prefix string, oldName string, newName string, dryRun bool) ([]TodoChange, error) {
+	for _, tagName := range []string{oldName, newName} {
+		if err := checkTagName(kind, tagName); err != nil {
+			return nil, err
+		}
+	}
+	if oldName == newName {
+		return nil, fmt.Errorf("The %s is already named \"%s\"", kind, newName)
+	}
+
+	changes := make([]TodoChange, 0)
+
+	rename := func(op *operation) error {
+		items, err := op.list()
+		if err != nil {
+			return err
+		}
+
+		for _, todo := range items {
+			var description string
+			if kind == "attribute" {
+				if _, ok := todo.Attributes[oldName]; ok && todo.hasAttribute(newName) {
+					return fmt.Errorf("Todo ID %d already has attribute \"%s\"", todo.TodoID, newName)
+				}
+				description = rewriteWords(todo.Description, func(word string) string {
+					if attributeKey(word) != oldName {
+						return word
+					}
+					return newName + word[len(oldName):]
+				})
+			} else {
+				// Keep a single tag when the todo already has the new one
+				renamed := false
+				for _, tok := range tokenize(todo.Description) {
+					renamed = renamed || tok.text == prefix+newName
+				}
+				description = rewriteWords(todo.Description, func(word string) string {
+					if word != prefix+oldName {
+						return word
+					} else if renamed {
+						return ""
+					}
+					renamed = true
+					return prefix + newName
+				})
+			}
+
+			if description == todo.Description {
+				continue
+			}
+
+			before := todo.String()
+			todo.setDescription(description)
+			changes = append(changes, TodoChange{TodoID: todo.TodoID, Before: before, After: todo.String()})
+
+			if !dryRun {
+				if err := op.update(todo.TodoID, todo); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(changes) == 0 {
+			return fmt.Errorf("No todos have %s \"%s\"", kind, oldName)
+		}
+
+		return nil
+	}
+
+	// A dry run only reads, so it doesn't need a batch
+	if dryRun {
+		return changes, rename(tm.begin(name))
+	}
+
+	return changes, tm.batch(name, rename)
+}
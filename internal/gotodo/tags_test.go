@@ -0,0 +1,113 @@
+package gotodo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteWords(t *testing.T) {
+	drop := func(word string) string {
+		if word == "+x" {
+			return ""
+		}
+		return strings.ToUpper(word)
+	}
+
+	assert.Equal(t, "A B", rewriteWords("a  +x b", drop))
+	assert.Equal(t, "A  B", rewriteWords("a  b +x", drop))
+	assert.Equal(t, "A\tB", rewriteWords("+x a\tb", drop))
+	assert.Equal(t, "A", rewriteWords("a +x", drop))
+	assert.Equal(t, "", rewriteWords("+x +x", drop))
+}
+
+// tagsTodos are the todos the tag tests start with
+var tagsTodos = []string{
+	"(B) Write  docs +gotodo +docs @home due:2020-06-01",
+	"x 2020-05-01 Fix bug +gotodo @work",
+	"Plan sprint +planning @work",
+}
+
+func TestRemoveTags(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, tagsTodos)
+
+	results, err := todoManager.RemoveProjectAll([]int{1, 2}, "gotodo")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results))
+	assert.NoError(t, todoManager.RemoveContext(3, "work"))
+	assert.NoError(t, todoManager.RemoveAttribute(1, "due"))
+
+	assert.Equal(t, strings.Join([]string{
+		"(B) Write  docs +docs @home",
+		"x 2020-05-01 Fix bug @work",
+		"Plan sprint +planning",
+	}, "\n")+"\n", readTestFile(t, storage))
+
+	todo, err := storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, false, todo.hasProject("gotodo"))
+	assert.Equal(t, false, todo.DueDate.Valid)
+
+	assert.EqualError(t, todoManager.RemoveProject(3, "gotodo"), "Todo isn't in project \"gotodo\"")
+	assert.EqualError(t, todoManager.RemoveAttribute(3, "due"), "Todo has no attribute \"due\"")
+}
+
+func TestRemoveTagsLastWord(t *testing.T) {
+	storage := getTestFileStorage(t, "(A) +gotodo")
+	todoManager := NewTodoManager(WithFileStorage(storage.Path))
+
+	assert.Equal(t, errNoDescription, todoManager.RemoveProject(1, "gotodo"))
+}
+
+func TestRenameProject(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, tagsTodos)
+	original := readTestFile(t, storage)
+
+	changes, err := todoManager.RenameProject("gotodo", "docs", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []TodoChange{
+		{TodoID: 1, Before: "(B) Write  docs +gotodo +docs @home due:2020-06-01", After: "(B) Write  docs +docs @home due:2020-06-01"},
+		{TodoID: 2, Before: "x 2020-05-01 Fix bug +gotodo @work", After: "x 2020-05-01 Fix bug +docs @work"},
+	}, changes)
+	assert.Equal(t, original, readTestFile(t, storage))
+
+	changes, err = todoManager.RenameProject("gotodo", "docs", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(changes))
+
+	items, err := todoManager.List(TodoListFilter{Status: ListAll, Project: "docs"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+
+	_, err = todoManager.RenameProject("gotodo", "docs", false)
+	assert.EqualError(t, err, "No todos have project \"gotodo\"")
+	_, err = todoManager.RenameProject("docs", "my docs", false)
+	assert.EqualError(t, err, "Invalid project name \"my docs\"")
+}
+
+func TestRenameContextAndAttribute(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, tagsTodos)
+
+	changes, err := todoManager.RenameContext("work", "office", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(changes))
+
+	changes, err = todoManager.RenameAttribute("due", "deadline", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(changes))
+
+	assert.Equal(t, strings.Join([]string{
+		"(B) Write  docs +gotodo +docs @home deadline:2020-06-01",
+		"x 2020-05-01 Fix bug +gotodo @office",
+		"Plan sprint +planning @office",
+	}, "\n")+"\n", readTestFile(t, storage))
+
+	todo, err := storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, false, todo.DueDate.Valid)
+	assert.Equal(t, "2020-06-01", todo.Attributes["deadline"])
+
+	_, err = todoManager.RenameAttribute("deadline", "a:b", false)
+	assert.EqualError(t, err, "Invalid attribute name \"a:b\", it can't contain a colon")
+}
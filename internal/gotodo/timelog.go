@@ -0,0 +1,368 @@
+package gotodo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// Session is a stretch of work on a Todo. Todo is the todo.txt string of the Todo when the
+// session last changed, so reports keep its projects and contexts after it is archived or removed.
+// List is the list the Todo belongs to, and End is zero while the session is active.
+type Session struct {
+	List   string    `json:"list,omitempty"`
+	TodoID int       `json:"id"`
+	Todo   string    `json:"todo"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// Active checks whether a Session is still running
+func (me Session) Active() bool {
+	return me.End.IsZero()
+}
+
+// Duration returns the length of a Session, counting an active session up to now
+func (me Session) Duration(now time.Time) time.Duration {
+	if me.Active() {
+		return now.Sub(me.Start)
+	}
+
+	return me.End.Sub(me.Start)
+}
+
+// TimeLog keeps the work sessions recorded by Start and Stop in a JSON file. Sessions are
+// recorded under List, so lists sharing a file only see their own sessions.
+type TimeLog struct {
+	Path string
+	List string
+}
+
+// timeLogState is the contents of a time log file
+type timeLogState struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// errNoTimeLog is returned when tracking time without a time log configured
+var errNoTimeLog = errors.New("No time log is configured")
+
+// load reads the time log file. A missing file is an empty log.
+func (me *TimeLog) load() (*timeLogState, error) {
+	state := &timeLogState{Sessions: make([]Session, 0)}
+
+	path, err := homedir.Expand(me.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(contents, state)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read time log %s: %s", me.Path, err)
+	}
+
+	return state, nil
+}
+
+// save writes the time log file
+func (me *TimeLog) save(state *timeLogState) error {
+	path, err := homedir.Expand(me.Path)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	// A time log cut short by a crash couldn't be read back, so never write it in place
+	return replaceFile(path, contents, 0600)
+}
+
+// Sessions returns every session of the log's list, oldest first
+func (me *TimeLog) Sessions() ([]Session, error) {
+	state, err := me.load()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(state.Sessions))
+	for _, session := range state.Sessions {
+		if me.owns(session) {
+			sessions = append(sessions, session)
+		}
+	}
+
+	return sessions, nil
+}
+
+// owns checks whether a session belongs to the log's list. Sessions recorded before they had a
+// list belong to every list.
+func (me *TimeLog) owns(session Session) bool {
+	return session.List == "" || session.List == me.List
+}
+
+// RenameList records the sessions of the list oldName under newName
+func (me *TimeLog) RenameList(oldName string, newName string) error {
+	state, err := me.load()
+	if err != nil {
+		return err
+	}
+
+	renamed := false
+	for i := range state.Sessions {
+		if state.Sessions[i].List == oldName {
+			state.Sessions[i].List = newName
+			renamed = true
+		}
+	}
+	if !renamed {
+		return nil
+	}
+
+	return me.save(state)
+}
+
+// Start begins a work session on a pending Todo. A Todo can only have one active session.
+func (tm *TodoManager) Start(todoID int) (Session, error) {
+	if tm.TimeLog == nil {
+		return Session{}, errNoTimeLog
+	}
+
+	todo, err := tm.Storage.Get(todoID)
+	if err != nil {
+		return Session{}, err
+	} else if todo.Complete {
+		return Session{}, fmt.Errorf("Todo ID %d is complete", todoID)
+	}
+
+	state, err := tm.TimeLog.load()
+	if err != nil {
+		return Session{}, err
+	}
+
+	for _, session := range state.Sessions {
+		if session.TodoID == todoID && session.Active() && tm.TimeLog.owns(session) {
+			return Session{}, fmt.Errorf("Todo ID %d was already started at %s", todoID, session.Start.Format("15:04"))
+		}
+	}
+
+	session := Session{List: tm.TimeLog.List, TodoID: todoID, Todo: todo.String(), Start: time.Now()}
+	state.Sessions = append(state.Sessions, session)
+
+	return session, tm.TimeLog.save(state)
+}
+
+// Stop ends the active work session on a Todo and returns it
+func (tm *TodoManager) Stop(todoID int) (Session, error) {
+	if tm.TimeLog == nil {
+		return Session{}, errNoTimeLog
+	}
+
+	state, err := tm.TimeLog.load()
+	if err != nil {
+		return Session{}, err
+	}
+
+	for i, session := range state.Sessions {
+		if session.TodoID != todoID || !session.Active() || !tm.TimeLog.owns(session) {
+			continue
+		}
+
+		// Keep the latest projects and contexts, unless the todo is gone
+		if todo, err := tm.Storage.Get(todoID); err == nil {
+			session.Todo = todo.String()
+		}
+		session.End = time.Now()
+		state.Sessions[i] = session
+
+		return session, tm.TimeLog.save(state)
+	}
+
+	return Session{}, fmt.Errorf("Todo ID %d isn't started", todoID)
+}
+
+// ActiveSessions returns the active work sessions by TodoID
+func (tm *TodoManager) ActiveSessions() (map[int]Session, error) {
+	active := make(map[int]Session)
+	if tm.TimeLog == nil {
+		return active, nil
+	}
+
+	sessions, err := tm.TimeLog.Sessions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		if session.Active() {
+			active[session.TodoID] = session
+		}
+	}
+
+	return active, nil
+}
+
+// moveSessions moves the sessions of a Todo to the time log target under a new ID and the list
+// of target. They are saved in target before being removed from the TodoManager's time log, so a
+// failure copies them rather than losing them.
+func (tm *TodoManager) moveSessions(todoID int, newID int, target *TimeLog) error {
+	if tm.TimeLog == nil || target == nil {
+		return nil
+	}
+
+	state, err := tm.TimeLog.load()
+	if err != nil {
+		return err
+	}
+
+	moved := make([]Session, 0)
+	kept := make([]Session, 0, len(state.Sessions))
+	for _, session := range state.Sessions {
+		if session.TodoID == todoID && tm.TimeLog.owns(session) {
+			session.List = target.List
+			session.TodoID = newID
+			moved = append(moved, session)
+		} else {
+			kept = append(kept, session)
+		}
+	}
+	if len(moved) == 0 {
+		return nil
+	}
+
+	// Lists sharing a time log only need the sessions relabelled, so newID can't be mistaken for
+	// a todo of the list they came from
+	if target.Path == tm.TimeLog.Path {
+		state.Sessions = append(kept, moved...)
+		return tm.TimeLog.save(state)
+	}
+
+	targetState, err := target.load()
+	if err != nil {
+		return err
+	}
+	targetState.Sessions = append(targetState.Sessions, moved...)
+	if err := target.save(targetState); err != nil {
+		return err
+	}
+
+	state.Sessions = kept
+	return tm.TimeLog.save(state)
+}
+
+// Report groups for TimeReport
+const (
+	ReportByProject = "project"
+	ReportByContext = "context"
+	ReportByDay     = "day"
+)
+
+// ReportRow is the time spent on a project, context or day
+type ReportRow struct {
+	Key  string
+	Time time.Duration
+}
+
+// TimeReport totals the time worked from the start of the day of from to the end of the day of
+// to, grouped by project, context or day. Time on a todo with several projects or contexts counts
+// towards each of them, and todos without any are grouped under an empty key. Rows are sorted by
+// key.
+func (tm *TodoManager) TimeReport(from time.Time, to time.Time, groupBy string) ([]ReportRow, error) {
+	if tm.TimeLog == nil {
+		return nil, errNoTimeLog
+	}
+
+	switch groupBy {
+	case ReportByProject, ReportByContext, ReportByDay:
+	default:
+		return nil, fmt.Errorf("Unsupported report \"%s\", use %s, %s or %s", groupBy, ReportByProject, ReportByContext, ReportByDay)
+	}
+
+	sessions, err := tm.TimeLog.Sessions()
+	if err != nil {
+		return nil, err
+	}
+
+	return timeReport(sessions, from, to, groupBy, time.Now()), nil
+}
+
+// timeReport totals sessions between the days of from and to, relative to now. Days start at
+// midnight in the time zone of from.
+func timeReport(sessions []Session, from time.Time, to time.Time, groupBy string, now time.Time) []ReportRow {
+	loc := from.Location()
+	rangeStart := midnight(from)
+	rangeEnd := midnight(to.In(loc)).AddDate(0, 0, 1)
+	totals := make(map[string]time.Duration)
+
+	for _, session := range sessions {
+		end := session.End
+		if session.Active() {
+			end = now
+		}
+
+		// Split the session at midnight so every day gets its share
+		for start := session.Start.In(loc); start.Before(end); {
+			dayEnd := midnight(start).AddDate(0, 0, 1)
+			if dayEnd.After(end) {
+				dayEnd = end.In(loc)
+			}
+
+			if !start.Before(rangeStart) && start.Before(rangeEnd) {
+				for _, key := range sessionKeys(session, groupBy, start) {
+					totals[key] += dayEnd.Sub(start)
+				}
+			}
+
+			start = dayEnd
+		}
+	}
+
+	rows := make([]ReportRow, 0, len(totals))
+	for key, total := range totals {
+		rows = append(rows, ReportRow{Key: key, Time: total})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Key < rows[j].Key
+	})
+
+	return rows
+}
+
+// sessionKeys returns the groups a session's time on a day counts towards
+func sessionKeys(session Session, groupBy string, day time.Time) []string {
+	var tags Tags
+
+	switch groupBy {
+	case ReportByDay:
+		return []string{day.Format(TimeFormat)}
+	case ReportByProject:
+		tags = FromString(session.Todo).Projects
+	case ReportByContext:
+		tags = FromString(session.Todo).Contexts
+	}
+
+	if len(tags) == 0 {
+		return []string{""}
+	}
+
+	return tags.Sorted()
+}
+
+// midnight returns the start of the day of t in its own time zone
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
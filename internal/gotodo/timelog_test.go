@@ -0,0 +1,159 @@
+package gotodo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartStop(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos, withTestJournal(0))
+	todoManager.TimeLog = &TimeLog{Path: filepath.Join(filepath.Dir(storage.Path), "time.json")}
+
+	session, err := todoManager.Start(1)
+	assert.NoError(t, err)
+	assert.Equal(t, true, session.Active())
+
+	_, err = todoManager.Start(1)
+	assert.Error(t, err)
+	_, err = todoManager.Start(5)
+	assert.Equal(t, ErrNotFound, err)
+
+	active, err := todoManager.ActiveSessions()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, activeIDs(active))
+
+	session, err = todoManager.Stop(1)
+	assert.NoError(t, err)
+	assert.Equal(t, false, session.Active())
+	assert.Equal(t, "(B) 2020-04-28 Work on unit tests @codehealth +gotodo", session.Todo)
+
+	_, err = todoManager.Stop(1)
+	assert.EqualError(t, err, "Todo ID 1 isn't started")
+
+	active, err = todoManager.ActiveSessions()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(active))
+
+	assert.NoError(t, todoManager.Complete(2))
+	_, err = todoManager.Start(2)
+	assert.EqualError(t, err, "Todo ID 2 is complete")
+}
+
+func TestTimeLogSaveReplacesFile(t *testing.T) {
+	todoManager, storage := getTestSeededManager(t, journalTodos)
+	path := filepath.Join(filepath.Dir(storage.Path), "time.json")
+	todoManager.TimeLog = &TimeLog{Path: path}
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"sessions":[]}`), 0640))
+
+	_, err := todoManager.Start(1)
+	assert.NoError(t, err)
+
+	// The time log is renamed into place, keeping its permissions
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode())
+
+	sessions, err := todoManager.TimeLog.Sessions()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sessions))
+}
+
+func TestSharedTimeLog(t *testing.T) {
+	work, storage := getTestSeededManager(t, []string{"Write report +client"})
+	path := filepath.Join(filepath.Dir(storage.Path), "time.json")
+	work.TimeLog = &TimeLog{Path: path, List: "Work"}
+	home, _ := getTestSeededManager(t, []string{"Water plants +garden"}, WithTimeLog(path, "Home"))
+
+	// Each list only sees the sessions of its own todos
+	_, err := work.Start(1)
+	assert.NoError(t, err)
+	session, err := home.Start(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Home", session.List)
+
+	active, err := work.ActiveSessions()
+	assert.NoError(t, err)
+	assert.Equal(t, "Write report +client", active[1].Todo)
+
+	session, err = home.Stop(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Water plants +garden", session.Todo)
+	_, err = home.Stop(1)
+	assert.EqualError(t, err, "Todo ID 1 isn't started")
+
+	rows, err := work.TimeReport(time.Now(), time.Now(), ReportByProject)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rows))
+	assert.Equal(t, "client", rows[0].Key)
+
+	// Renaming a list keeps its sessions
+	assert.NoError(t, work.TimeLog.RenameList("Work", "Office"))
+	work.TimeLog.List = "Office"
+	active, err = work.ActiveSessions()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, activeIDs(active))
+}
+
+func activeIDs(active map[int]Session) []int {
+	ids := make([]int, 0, len(active))
+	for todoID := range active {
+		ids = append(ids, todoID)
+	}
+	return ids
+}
+
+func TestTimeReport(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		assert.NoError(t, err)
+		return parsed
+	}
+
+	sessions := []Session{
+		{TodoID: 1, Todo: "Invoice +acme +billing @office", Start: at("2020-05-01 09:00"), End: at("2020-05-01 10:30")},
+		{TodoID: 2, Todo: "Fix bug +acme", Start: at("2020-05-01 23:00"), End: at("2020-05-02 01:00")},
+		{TodoID: 3, Todo: "Read mail", Start: at("2020-05-03 08:00"), End: at("2020-05-03 08:15")},
+		{TodoID: 2, Todo: "Fix bug +acme", Start: at("2020-05-04 08:00")},
+	}
+	now := at("2020-05-04 09:00")
+
+	rows := timeReport(sessions, at("2020-05-01 00:00"), at("2020-05-04 00:00"), ReportByDay, now)
+	assert.Equal(t, []ReportRow{
+		{Key: "2020-05-01", Time: 150 * time.Minute},
+		{Key: "2020-05-02", Time: time.Hour},
+		{Key: "2020-05-03", Time: 15 * time.Minute},
+		{Key: "2020-05-04", Time: time.Hour},
+	}, rows)
+
+	// Only the part of a session inside the range counts
+	rows = timeReport(sessions, at("2020-05-02 00:00"), at("2020-05-03 00:00"), ReportByProject, now)
+	assert.Equal(t, []ReportRow{
+		{Key: "", Time: 15 * time.Minute},
+		{Key: "acme", Time: time.Hour},
+	}, rows)
+
+	rows = timeReport(sessions, at("2020-05-01 00:00"), at("2020-05-01 00:00"), ReportByProject, now)
+	assert.Equal(t, []ReportRow{
+		{Key: "acme", Time: 150 * time.Minute},
+		{Key: "billing", Time: 90 * time.Minute},
+	}, rows)
+
+	rows = timeReport(sessions, at("2020-05-01 00:00"), at("2020-05-01 00:00"), ReportByContext, now)
+	assert.Equal(t, []ReportRow{
+		{Key: "", Time: time.Hour},
+		{Key: "office", Time: 90 * time.Minute},
+	}, rows)
+}
+
+func TestTimeReportUnsupported(t *testing.T) {
+	todoManager, _ := getTestSeededManager(t, journalTodos, withTestJournal(0))
+	todoManager.TimeLog = &TimeLog{Path: "unused.json"}
+
+	_, err := todoManager.TimeReport(time.Now(), time.Now(), "week")
+	assert.EqualError(t, err, "Unsupported report \"week\", use project, context or day")
+}
@@ -3,6 +3,7 @@ package gotodo
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Tags is a key/value store for custom Todo metadata
@@ -11,7 +12,8 @@ type Tags map[string]void
 // Attributes is a key/value store for custom Todo metadata
 type Attributes map[string]string
 
-// Todo contains information about a specific Todo
+// Todo contains information about a specific Todo. A Todo parsed by FromString remembers the line
+// it came from, so String returns that line byte for byte until the Todo is changed.
 type Todo struct {
 	TodoID         int
 	Complete       bool
@@ -19,103 +21,80 @@ type Todo struct {
 	CompletionDate NullTime
 	CreationDate   NullTime
 	DueDate        NullTime
+	ThresholdDate  NullTime
 	Description    string
 	Projects       Tags
 	Contexts       Tags
 	Attributes     Attributes
+	raw            string
+	rawCanonical   string
 }
 
-// FromString translates a todotxt string into a *Todo
+// FromString translates a todotxt string into a *Todo. The completion mark, priority and dates
+// are only recognized when more text follows them, so a lone "x" or date is a description.
 func FromString(todoStr string) *Todo {
-	parts := strings.Split(strings.TrimSpace(todoStr), " ")
-	var complete bool
-	var priority int
-	var completionDate NullTime
-	var creationDate NullTime
-	var dueDate NullTime
-	var description string
-
-	// If we have more than 1 part and the first part is x, consider the Todo complete.
-	complete = false
-	if len(parts) > 1 && isCompleteToken(parts[0]) {
-		complete = true
-		parts = parts[1:]
-	}
-
-	// If we have more than 1 part and the first part is a valid priority flag, translate
-	// the priority.
-	priority = 0
-	if len(parts) > 1 && isPriorityToken(parts[0]) {
-		end := len(parts[0])
-		arg := parts[0][1 : end-1]
-		priority = parsePriority(arg)
-		parts = parts[1:]
-	}
-
-	// Check for zero, one or two times. If there are zero, we move along. If there is one time,
-	// it is creation time. If there are two times and the todo is complete, the first is completed
-	// time and the second is created.
-	if len(parts) > 1 {
-		firstTime := parseDate(parts[0])
+	tokens := tokenize(todoStr)
+	todo := &Todo{raw: todoStr}
+	next := 0
+
+	// A line starting with an x followed by more text is complete
+	if len(tokens) > next+1 && isCompleteToken(tokens[next].text) {
+		todo.Complete = true
+		next++
+	}
+
+	// A priority flag followed by more text sets the priority
+	if len(tokens) > next+1 && isPriorityToken(tokens[next].text) {
+		text := tokens[next].text
+		todo.Priority = parsePriority(text[1 : len(text)-1])
+		next++
+	}
+
+	// A complete todo has a completion date and then an optional creation date, while a pending
+	// todo only has the creation date. Like the other fields, a date must be followed by more text.
+	if len(tokens) > next+1 {
+		firstTime := parseDate(tokens[next].text)
 		if firstTime.Valid {
-			if len(parts) > 2 {
-				secondTime := parseDate(parts[1])
-				if secondTime.Valid && complete {
-					completionDate = firstTime
-					creationDate = secondTime
-					parts = parts[2:]
-				} else {
-					creationDate = firstTime
-					parts = parts[1:]
-				}
+			next++
+			if !todo.Complete {
+				todo.CreationDate = firstTime
 			} else {
-				creationDate = firstTime
-				parts = parts[1:]
+				todo.CompletionDate = firstTime
+				if len(tokens) > next+1 {
+					if secondTime := parseDate(tokens[next].text); secondTime.Valid {
+						todo.CreationDate = secondTime
+						next++
+					}
+				}
 			}
 		}
 	}
 
-	projects, contexts := parseTags(parts)
-	customAttrs := make(Attributes)
-
-	for i := range parts {
-		idx := len(parts) - i - 1
-		word := parts[idx]
-		if strings.Contains(word, ":") {
-			attr := strings.Split(word, ":")
-			// If the attribute has multiple colons, use the first part as key and concat the rest
-			// as value
-			key := attr[0]
-			value := strings.Join(attr[1:], ":")
-			customAttrs[key] = value
-		} else {
-			break
-		}
+	// The description is the rest of the line as written, with inner whitespace intact
+	description := ""
+	if next < len(tokens) {
+		description = strings.TrimRight(todoStr[tokens[next].start:], " \t")
 	}
+	todo.setDescription(description)
 
-	// Due date is a special attribute in todo.txt. It's not part of the official spec, but has
-	// gained enough traction in the community that it gets special attention.
-	if dueAttr, ok := customAttrs["due"]; ok {
-		dueDate = parseDate(dueAttr)
-	}
+	todo.rawCanonical = todo.canonical()
 
-	description = strings.Join(parts, " ")
+	return todo
+}
 
-	return &Todo{
-		Complete:       complete,
-		Priority:       priority,
-		CompletionDate: completionDate,
-		CreationDate:   creationDate,
-		DueDate:        dueDate,
-		Description:    description,
-		Projects:       projects,
-		Contexts:       contexts,
-		Attributes:     customAttrs,
+// String converts a Todo into a todotxt-formatted string. A Todo that hasn't changed since it was
+// parsed returns its original line.
+func (t *Todo) String() string {
+	canonical := t.canonical()
+	if t.raw != "" && canonical == t.rawCanonical {
+		return t.raw
 	}
+
+	return canonical
 }
 
-// String converts a Todo into a todotxt-formatted string
-func (t *Todo) String() string {
+// canonical builds a todotxt-formatted string from the fields of a Todo, separated by single spaces
+func (t *Todo) canonical() string {
 	parts := make([]string, 0)
 
 	if t.Complete {
@@ -140,6 +119,72 @@ func (t *Todo) String() string {
 	return strings.Join(parts, " ")
 }
 
+// setDescription replaces the description of a Todo along with the projects, contexts and
+// attributes found in it
+func (t *Todo) setDescription(description string) {
+	words := make([]string, 0)
+	for _, tok := range tokenize(description) {
+		words = append(words, tok.text)
+	}
+
+	t.Description = description
+	t.Projects, t.Contexts = parseTags(words)
+	t.Attributes = parseAttributes(words)
+
+	// Due date is a special attribute in todo.txt. It's not part of the official spec, but has
+	// gained enough traction in the community that it gets special attention.
+	t.DueDate = InvalidTime
+	if dueAttr, ok := t.Attributes["due"]; ok {
+		t.DueDate = parseDate(dueAttr)
+	}
+
+	// Threshold date is another community extension. A todo isn't actionable until its threshold.
+	t.ThresholdDate = InvalidTime
+	if thresholdAttr, ok := t.Attributes["t"]; ok {
+		t.ThresholdDate = parseDate(thresholdAttr)
+	}
+}
+
+// setAttribute sets the value of an attribute, replacing the key:value pair in the description or
+// adding one to the end of it
+func (t *Todo) setAttribute(key string, value string) {
+	attr := key + ":" + value
+	words := strings.Split(t.Description, " ")
+	found := false
+
+	for i, word := range words {
+		if strings.HasPrefix(word, key+":") {
+			words[i] = attr
+			found = true
+		}
+	}
+
+	if found {
+		t.Description = strings.Join(words, " ")
+	} else if t.Description == "" {
+		t.Description = attr
+	} else {
+		t.Description = t.Description + " " + attr
+	}
+
+	if t.Attributes == nil {
+		t.Attributes = make(Attributes)
+	}
+	t.Attributes[key] = value
+
+	switch key {
+	case "due":
+		t.DueDate = parseDate(value)
+	case "t":
+		t.ThresholdDate = parseDate(value)
+	}
+}
+
+// isFuture checks whether a todo's threshold date is after the day of now
+func (t *Todo) isFuture(now time.Time) bool {
+	return t.ThresholdDate.Valid && t.ThresholdDate.Time.After(dateOf(now))
+}
+
 // hasProject checks a todo.Projects for a specific project
 func (t *Todo) hasProject(project string) bool {
 	if len(t.Projects) == 0 {
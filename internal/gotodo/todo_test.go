@@ -1,13 +1,20 @@
 package gotodo
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+var update = flag.Bool("update", false, "update golden files")
+
 func TestString(t *testing.T) {
 	now := time.Now()
 	displayTime := now.Format(TimeFormat)
@@ -180,3 +187,85 @@ func TestHasAttribute(t *testing.T) {
 	assert.Equal(t, false, todo.hasAttribute("gotodo"))
 	assert.Equal(t, true, todo.hasAttribute("due"))
 }
+
+func TestFromStringThreshold(t *testing.T) {
+	todo := FromString("Renew passport t:2020-06-01 due:2020-07-01")
+	assert.Equal(t, true, todo.ThresholdDate.Valid)
+	assert.Equal(t, "2020-06-01", todo.ThresholdDate.Display())
+	assert.Equal(t, "2020-07-01", todo.DueDate.Display())
+
+	todo = FromString("Renew passport t:someday")
+	assert.Equal(t, false, todo.ThresholdDate.Valid)
+}
+
+func TestIsFuture(t *testing.T) {
+	now, err := time.Parse(TimeFormat, "2020-06-01")
+	assert.NoError(t, err)
+
+	assert.Equal(t, false, FromString("Renew passport").isFuture(now))
+	assert.Equal(t, false, FromString("Renew passport t:2020-05-31").isFuture(now))
+	assert.Equal(t, false, FromString("Renew passport t:2020-06-01").isFuture(now))
+	assert.Equal(t, true, FromString("Renew passport t:2020-06-02").isFuture(now))
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []token{
+		{text: "(A)", start: 1, end: 4},
+		{text: "Call", start: 6, end: 10},
+		{text: "Mom", start: 11, end: 14},
+	}, tokenize(" (A)  Call\tMom "))
+	assert.Equal(t, []token{}, tokenize("   "))
+}
+
+func TestRoundTrip(t *testing.T) {
+	contents, err := ioutil.ReadFile(filepath.Join("testdata", "roundtrip.txt"))
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+
+	// Every line must come back byte for byte, and parse the same way as recorded in the golden file
+	todos := make([]*Todo, len(lines))
+	for i, line := range lines {
+		todos[i] = FromString(line)
+		assert.Equal(t, line, todos[i].String())
+		assert.Equal(t, line, FromString(todos[i].String()).String())
+	}
+
+	parsed, err := json.MarshalIndent(todos, "", "  ")
+	assert.NoError(t, err)
+
+	golden := filepath.Join("testdata", "roundtrip.golden.json")
+	if *update {
+		assert.NoError(t, ioutil.WriteFile(golden, append(parsed, '\n'), 0644))
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(parsed)+"\n")
+}
+
+func TestStringChanged(t *testing.T) {
+	todo := FromString("(A)  2020-04-28  Call   Mom")
+	assert.Equal(t, "Call   Mom", todo.Description)
+
+	// changing a field rebuilds the header, but keeps the description as written
+	todo.Priority = 2
+	assert.Equal(t, "(B) 2020-04-28 Call   Mom", todo.String())
+
+	todo.Priority = 1
+	assert.Equal(t, "(A)  2020-04-28  Call   Mom", todo.String())
+}
+
+func TestFromStringAttributes(t *testing.T) {
+	todo := FromString("Pay rent due:2020-05-01 before the weekend http://example.com")
+	assert.Equal(t, Attributes{"due": "2020-05-01"}, todo.Attributes)
+	assert.Equal(t, "2020-05-01", todo.DueDate.Display())
+
+	todo = FromString("x 2020-04-29 Add storage test")
+	assert.Equal(t, "2020-04-29", todo.CompletionDate.Display())
+	assert.Equal(t, false, todo.CreationDate.Valid)
+
+	todo = FromString("(Z) Lowest priority")
+	assert.Equal(t, 26, todo.Priority)
+	todo.Priority = 27
+	assert.Equal(t, "(AA) Lowest priority", todo.String())
+}
@@ -1,6 +1,11 @@
 package gotodo
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -11,31 +16,107 @@ type void struct{}
 // TodoList is list of Todos
 type TodoList []*Todo
 
-// TodoListFilter provides filtering criteria for a TodoList
+// TodoListFilter provides filtering criteria for a TodoList. Query is parsed with ParseQuery.
+// Pending todos with a threshold date in the future are hidden unless ShowFuture is set, and
+// pending todos waiting for others to be completed are hidden unless ShowBlocked is set.
 type TodoListFilter struct {
-	Status    int
-	Project   string
-	Context   string
-	Attribute string
+	Status      int
+	Project     string
+	Context     string
+	Attribute   string
+	Query       string
+	ShowFuture  bool
+	ShowBlocked bool
 }
 
 // TodoManager controls a TodoList
 type TodoManager struct {
 	Storage               Storage
+	ArchiveStorage        Storage
+	AutoArchive           bool
+	Journal               *Journal
+	TimeLog               *TimeLog
 	DuePrioritizationRate int
 }
 
 // TodoManagerOptions provides functional options to TodoManager
 type TodoManagerOptions func(*TodoManager)
 
-// WithBoltStorage configures a BoltStorage instance for TodoManager
+// errNoArchive is returned when archiving without an archive storage configured
+var errNoArchive = errors.New("No archive is configured")
+
+// archiveBucketSuffix is appended to a bucket name to name its archive bucket
+const archiveBucketSuffix = "Archive"
+
+// doneFile is the name of the todo.txt archive file, kept in the same directory as todo.txt
+const doneFile = "done.txt"
+
+// WithBoltStorage configures a BoltStorage instance for TodoManager. Completed todos are
+// archived to a bucket of the same name with an "Archive" suffix.
 func WithBoltStorage(bucket string) TodoManagerOptions {
 	return func(tm *TodoManager) {
 		tm.Storage = &BoltStorage{Bucket: []byte(bucket)}
+		tm.ArchiveStorage = &BoltStorage{Bucket: []byte(bucket + archiveBucketSuffix)}
+	}
+}
+
+// WithFileStorage configures a FileStorage instance for TodoManager. Completed todos are
+// archived to done.txt in the same directory.
+func WithFileStorage(path string) TodoManagerOptions {
+	return func(tm *TodoManager) {
+		tm.Storage = &FileStorage{Path: path}
+		tm.ArchiveStorage = &FileStorage{Path: filepath.Join(filepath.Dir(path), doneFile)}
+	}
+}
+
+// WithMemoryStorage configures a MemoryStorage instance for TodoManager, with another for
+// archived todos. Nothing is saved once the TodoManager is gone.
+func WithMemoryStorage() TodoManagerOptions {
+	return func(tm *TodoManager) {
+		tm.Storage = &MemoryStorage{}
+		tm.ArchiveStorage = &MemoryStorage{}
+	}
+}
+
+// WithBoltArchive configures a BoltStorage instance for archived todos
+func WithBoltArchive(bucket string) TodoManagerOptions {
+	return func(tm *TodoManager) {
+		tm.ArchiveStorage = &BoltStorage{Bucket: []byte(bucket)}
+	}
+}
+
+// WithFileArchive configures a FileStorage instance for archived todos
+func WithFileArchive(path string) TodoManagerOptions {
+	return func(tm *TodoManager) {
+		tm.ArchiveStorage = &FileStorage{Path: path}
+	}
+}
+
+// WithAutoArchive configures whether todos are archived as soon as they are completed
+func WithAutoArchive(autoArchive bool) TodoManagerOptions {
+	return func(tm *TodoManager) {
+		tm.AutoArchive = autoArchive
 	}
 }
 
-// WithDuePrioritization configures due prioritization
+// WithJournal records every change in a journal file so it can be undone. The journal keeps the
+// most recent limit operations, or every operation when limit is 0.
+func WithJournal(path string, limit int) TodoManagerOptions {
+	return func(tm *TodoManager) {
+		tm.Journal = &Journal{Path: path, Limit: limit}
+	}
+}
+
+// WithTimeLog records the work sessions started and stopped on todos in a time log file, under
+// the name of their list so several lists can share the file
+func WithTimeLog(path string, list string) TodoManagerOptions {
+	return func(tm *TodoManager) {
+		tm.TimeLog = &TimeLog{Path: path, List: list}
+	}
+}
+
+// WithDuePrioritization configures due prioritization. Every rate days closer to its due
+// date raises a Todo's effective priority by one letter. A rate of 0 disables it.
 func WithDuePrioritization(rate int) TodoManagerOptions {
 	return func(tm *TodoManager) {
 		tm.DuePrioritizationRate = rate
@@ -50,6 +131,7 @@ func NewTodoManager(opts ...TodoManagerOptions) *TodoManager {
 
 	tm := &TodoManager{
 		Storage:               &BoltStorage{Bucket: []byte("Todos")},
+		ArchiveStorage:        &BoltStorage{Bucket: []byte("Todos" + archiveBucketSuffix)},
 		DuePrioritizationRate: defaultDuePrioritizationRate,
 	}
 
@@ -60,16 +142,49 @@ func NewTodoManager(opts ...TodoManagerOptions) *TodoManager {
 	return tm
 }
 
+// Close releases any resources held by the storage and archive, such as an open database
+func (tm *TodoManager) Close() error {
+	var err error
+
+	for _, storage := range []Storage{tm.Storage, tm.ArchiveStorage} {
+		if closer, ok := storage.(io.Closer); ok {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	}
+
+	return err
+}
+
 // List returns a slice of Todos as determined by TodoListFilter criteria
 func (tm *TodoManager) List(listFilter TodoListFilter) (TodoList, error) {
 	var err error
 
-	items, err := tm.Storage.List()
+	storage := tm.Storage
+	if listFilter.Status == ListArchived {
+		if tm.ArchiveStorage == nil {
+			return make(TodoList, 0), errNoArchive
+		}
+		storage = tm.ArchiveStorage
+	}
+
+	var query QueryNode
+	if listFilter.Query != "" {
+		query, err = ParseQuery(listFilter.Query)
+		if err != nil {
+			return make(TodoList, 0), err
+		}
+	}
+
+	items, err := storage.List()
 	if err != nil {
 		return items, err
 	}
 
 	itemsToDisplay := make(TodoList, 0)
+	now := time.Now()
+	links := NewTodoLinks(items)
 
 	for _, todo := range items {
 		if listFilter.Status == ListPending && todo.Complete {
@@ -78,6 +193,14 @@ func (tm *TodoManager) List(listFilter TodoListFilter) (TodoList, error) {
 			continue
 		}
 
+		if !listFilter.ShowFuture && !todo.Complete && todo.isFuture(now) {
+			continue
+		}
+
+		if !listFilter.ShowBlocked && listFilter.Status != ListArchived && len(links.BlockedBy(todo.TodoID)) > 0 {
+			continue
+		}
+
 		if listFilter.Project != "" && !todo.hasProject(listFilter.Project) {
 			continue
 		}
@@ -90,169 +213,417 @@ func (tm *TodoManager) List(listFilter TodoListFilter) (TodoList, error) {
 			continue
 		}
 
+		if query != nil && !query.Match(todo) {
+			continue
+		}
+
 		itemsToDisplay = append(itemsToDisplay, todo)
 	}
 
 	return itemsToDisplay, nil
 }
 
+// EffectivePriority returns the priority of a Todo once due prioritization is applied. The
+// stored priority is never lowered, and overdue todos are always priority A.
+func (tm *TodoManager) EffectivePriority(todo *Todo) int {
+	return tm.effectivePriority(todo, time.Now())
+}
+
+// effectivePriority computes the effective priority of a Todo relative to now
+func (tm *TodoManager) effectivePriority(todo *Todo, now time.Time) int {
+	if tm.DuePrioritizationRate <= 0 || todo.Complete || !todo.DueDate.Valid {
+		return todo.Priority
+	}
+
+	if tm.overdue(todo, now) {
+		return 1
+	}
+
+	// Due dates have no time component, so compare whole days
+	days := int(todo.DueDate.Time.Sub(dateOf(now)).Hours() / 24)
+
+	index := days / tm.DuePrioritizationRate
+	if index >= len(letters) {
+		return todo.Priority
+	}
+
+	priority := index + 1
+	if todo.Priority > 0 && todo.Priority < priority {
+		return todo.Priority
+	}
+
+	return priority
+}
+
+// Overdue reports whether a pending Todo is past its due date while due prioritization is on.
+// Overdue todos share priority A with todos due soon, but rank ahead of them when sorted.
+func (tm *TodoManager) Overdue(todo *Todo) bool {
+	return tm.overdue(todo, time.Now())
+}
+
+// overdue reports whether a Todo is overdue relative to now
+func (tm *TodoManager) overdue(todo *Todo, now time.Time) bool {
+	if tm.DuePrioritizationRate <= 0 || todo.Complete || !todo.DueDate.Valid {
+		return false
+	}
+
+	// Due dates have no time component, so compare whole days
+	return int(todo.DueDate.Time.Sub(dateOf(now)).Hours()/24) < 0
+}
+
+// Escalate saves the effective priority of every pending Todo whose due date has raised it
+// above its stored priority. It returns the number of todos updated.
+func (tm *TodoManager) Escalate() (int, error) {
+	escalated := 0
+
+	err := tm.batch("escalate", func(op *operation) error {
+		items, err := op.list()
+		if err != nil {
+			return err
+		}
+
+		for _, todo := range items {
+			priority := tm.EffectivePriority(todo)
+			if priority == todo.Priority {
+				continue
+			}
+
+			todo.Priority = priority
+			err = op.update(todo.TodoID, todo)
+			if err != nil {
+				return err
+			}
+			escalated++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return escalated, nil
+}
+
+// Query returns every pending and completed Todo matching a query, including those with a future
+// threshold date. See ParseQuery for the syntax.
+func (tm *TodoManager) Query(query string) (TodoList, error) {
+	return tm.List(TodoListFilter{Status: ListAll, Query: query, ShowFuture: true, ShowBlocked: true})
+}
+
 // Add takes a todotxt string and adds it to the list of todos
 func (tm *TodoManager) Add(todoStr string) (int, error) {
 	todo := FromString(todoStr)
-	return todo.TodoID, tm.Storage.Create(todo)
+
+	err := tm.batch("add", func(op *operation) error {
+		return op.create(todo)
+	})
+
+	return todo.TodoID, err
 }
 
 // Update takes the ID number of an existing Todo and a parseable todo string and replaces all
 // contents of the existing todo with the update.
 func (tm *TodoManager) Update(todoID int, todoStr string) error {
-	todo, err := tm.Storage.Get(todoID)
-	if err != nil {
-		return err
+	return tm.modify("edit", todoID, func(todo *Todo) error {
+		// Replace every field, including the line the todo was parsed from, so the update is saved
+		// exactly as written
+		*todo = *FromString(todoStr)
+		todo.TodoID = todoID
+
+		return nil
+	})
+}
+
+// TodoPatch is a set of changes Patch makes to a Todo. Nil fields are left as they are, and an
+// empty Priority removes the priority.
+type TodoPatch struct {
+	Text     *string
+	Priority *string
+	Complete *bool
+}
+
+// Patch replaces the text of a Todo, then sets its priority and completion, as a single
+// operation. Either every change is saved or none are, and they are undone together.
+func (tm *TodoManager) Patch(todoID int, patch TodoPatch) error {
+	if patch.Text != nil {
+		if err := ValidateTodo(*patch.Text); err != nil {
+			return err
+		}
+	}
+	if patch.Priority != nil && *patch.Priority != "" && !IsPriorityString(*patch.Priority) {
+		return fmt.Errorf("Invalid priority value: %s", *patch.Priority)
 	}
 
-	newTodo := FromString(todoStr)
+	return tm.batch("patch", func(op *operation) error {
+		if patch.Text != nil || patch.Priority != nil || (patch.Complete != nil && !*patch.Complete) {
+			err := op.modify(todoID, func(todo *Todo) error {
+				if patch.Text != nil {
+					*todo = *FromString(*patch.Text)
+					todo.TodoID = todoID
+				}
+				if patch.Priority != nil {
+					todo.Priority = parsePriority(*patch.Priority)
+				}
+				if patch.Complete != nil && !*patch.Complete {
+					todo.Complete = false
+					todo.CompletionDate = InvalidTime
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
 
-	todo.Complete = newTodo.Complete
-	todo.Priority = newTodo.Priority
-	todo.Description = newTodo.Description
-	todo.CompletionDate = newTodo.CompletionDate
-	todo.CreationDate = newTodo.CreationDate
-	todo.Projects = newTodo.Projects
-	todo.Contexts = newTodo.Contexts
-	todo.Attributes = newTodo.Attributes
+		if patch.Complete != nil && *patch.Complete {
+			return tm.complete(op, todoID)
+		}
 
-	return tm.Storage.Update(todoID, todo)
+		return nil
+	})
 }
 
 // Prepend adds a string message to the front of a todo description
 func (tm *TodoManager) Prepend(todoID int, prependStr string) error {
-	todo, err := tm.Storage.Get(todoID)
-	if err != nil {
-		return err
-	}
-
-	todo.Description = prependStr + " " + todo.Description
-
-	return tm.Storage.Update(todoID, todo)
+	return tm.modify("prepend", todoID, func(todo *Todo) error {
+		todo.setDescription(prependStr + " " + todo.Description)
+		return nil
+	})
 }
 
 // Append adds a string message to the end of a todo description
 func (tm *TodoManager) Append(todoID int, appendStr string) error {
-	todo, err := tm.Storage.Get(todoID)
-	if err != nil {
-		return err
-	}
-
-	todo.Description = todo.Description + " " + appendStr
-
-	return tm.Storage.Update(todoID, todo)
+	return tm.modify("append", todoID, func(todo *Todo) error {
+		todo.setDescription(todo.Description + " " + appendStr)
+		return nil
+	})
 }
 
 // Prioritize changes the priority of a Todo identified by todoID
 func (tm *TodoManager) Prioritize(todoID int, priorityString string) error {
-	todo, err := tm.Storage.Get(todoID)
-	if err != nil {
-		return err
-	}
-
-	priority := parsePriority(priorityString)
-	todo.Priority = priority
+	return singleResult(tm.PrioritizeAll([]int{todoID}, priorityString))
+}
 
-	return tm.Storage.Update(todoID, todo)
+// PrioritizeAll changes the priority of every Todo identified by todoIDs in a single batch
+func (tm *TodoManager) PrioritizeAll(todoIDs []int, priorityString string) ([]BulkResult, error) {
+	return tm.modifyAll("prioritize", todoIDs, func(todo *Todo) error {
+		priority := parsePriority(priorityString)
+		todo.Priority = priority
+		return nil
+	})
 }
 
 // Deprioritize changes the priority of a Todo identified by todoID
 func (tm *TodoManager) Deprioritize(todoID int) error {
-	todo, err := tm.Storage.Get(todoID)
-	if err != nil {
-		return err
-	}
-
-	todo.Priority = 0
+	return singleResult(tm.DeprioritizeAll([]int{todoID}))
+}
 
-	return tm.Storage.Update(todoID, todo)
+// DeprioritizeAll removes the priority of every Todo identified by todoIDs in a single batch
+func (tm *TodoManager) DeprioritizeAll(todoIDs []int) ([]BulkResult, error) {
+	return tm.modifyAll("deprioritize", todoIDs, func(todo *Todo) error {
+		todo.Priority = 0
+		return nil
+	})
 }
 
 // AddProject adds a project tag to a todo
 func (tm *TodoManager) AddProject(todoID int, project string) error {
-	todo, err := tm.Storage.Get(todoID)
-	if err != nil {
-		return err
-	}
-
-	if _, ok := todo.Projects[project]; !ok {
-		todo.Projects[project] = void{}
-		todo.Description = todo.Description + " +" + project
-	}
+	return singleResult(tm.AddProjectAll([]int{todoID}, project))
+}
 
-	return tm.Storage.Update(todoID, todo)
+// AddProjectAll adds a project tag to every Todo identified by todoIDs in a single batch
+func (tm *TodoManager) AddProjectAll(todoIDs []int, project string) ([]BulkResult, error) {
+	return tm.modifyAll("addproject", todoIDs, func(todo *Todo) error {
+		if _, ok := todo.Projects[project]; !ok {
+			todo.setDescription(todo.Description + " +" + project)
+		}
+		return nil
+	})
 }
 
 // AddContext adds a context tag to a todo
 func (tm *TodoManager) AddContext(todoID int, context string) error {
-	todo, err := tm.Storage.Get(todoID)
-	if err != nil {
-		return err
-	}
-
-	if _, ok := todo.Contexts[context]; !ok {
-		todo.Contexts[context] = void{}
-		todo.Description = todo.Description + " @" + context
-	}
+	return singleResult(tm.AddContextAll([]int{todoID}, context))
+}
 
-	return tm.Storage.Update(todoID, todo)
+// AddContextAll adds a context tag to every Todo identified by todoIDs in a single batch
+func (tm *TodoManager) AddContextAll(todoIDs []int, context string) ([]BulkResult, error) {
+	return tm.modifyAll("addcontext", todoIDs, func(todo *Todo) error {
+		if _, ok := todo.Contexts[context]; !ok {
+			todo.setDescription(todo.Description + " @" + context)
+		}
+		return nil
+	})
 }
 
-// AddAttribute adds a context tag to a todo
+// AddAttribute adds a key:value attribute to a todo. The description is parsed again, so the
+// attribute is read exactly as it would be from a todo.txt file.
 func (tm *TodoManager) AddAttribute(todoID int, attr string) error {
-	todo, err := tm.Storage.Get(todoID)
-	if err != nil {
-		return err
-	}
+	return tm.modify("addattribute", todoID, func(todo *Todo) error {
+		if strings.Contains(attr, ":") {
+			todo.setDescription(todo.Description + " " + attr)
+		}
+		return nil
+	})
+}
 
-	if strings.Contains(attr, ":") {
-		parts := strings.Split(attr, ":")
-		// If the attribute has multiple colons, use the first part as key and concat the rest
-		// as value
-		key := parts[0]
-		value := strings.Join(parts[1:], ":")
+// Complete changes the completion status of a Todo to done and adds CompletionDate. Completing a
+// recurring Todo (one with a rec: attribute) adds its next occurrence as a new pending Todo.
+func (tm *TodoManager) Complete(todoID int) error {
+	return singleResult(tm.CompleteAll([]int{todoID}))
+}
 
-		todo.Attributes[key] = value
-		todo.Description = todo.Description + " " + attr
-	}
+// CompleteAll completes every Todo identified by todoIDs in a single batch
+func (tm *TodoManager) CompleteAll(todoIDs []int) ([]BulkResult, error) {
+	return tm.bulk("complete", todoIDs, tm.complete)
+}
+
+// CompleteCascade completes every Todo identified by todoIDs along with all of their pending
+// subtasks, in a single batch
+func (tm *TodoManager) CompleteCascade(todoIDs []int) ([]BulkResult, error) {
+	completed := make(map[int]void)
+
+	return tm.bulk("complete", todoIDs, func(op *operation, todoID int) error {
+		if _, ok := completed[todoID]; ok {
+			return nil
+		}
+
+		// Find the subtasks first, as completing the todo may archive it
+		items, err := op.list()
+		if err != nil {
+			return err
+		}
+		links := NewTodoLinks(items)
+
+		for _, completeID := range append([]int{todoID}, links.Descendants(todoID)...) {
+			if _, ok := completed[completeID]; ok {
+				continue
+			}
+			if completeID != todoID && links.todos[completeID].Complete {
+				continue
+			}
+
+			err := tm.complete(op, completeID)
+			if err != nil {
+				return err
+			}
+			completed[completeID] = void{}
+		}
 
-	return tm.Storage.Update(todoID, todo)
+		return nil
+	})
 }
 
-// Complete changes the completion status of a Todo to done and adds CompletionDate
-func (tm *TodoManager) Complete(todoID int) error {
-	todo, err := tm.Storage.Get(todoID)
+// complete marks a Todo as done as part of an operation
+func (tm *TodoManager) complete(op *operation, todoID int) error {
+	todo, err := op.get(todoID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	next, err := nextOccurrence(todo, now)
 	if err != nil {
 		return err
 	}
 
 	todo.Complete = true
-	todo.CompletionDate = ValidTime(time.Now())
+	todo.CompletionDate = ValidTime(now)
 	todo.Priority = 0
 
-	return tm.Storage.Update(todoID, todo)
+	err = op.update(todoID, todo)
+	if err != nil {
+		return err
+	}
+
+	if next != nil {
+		err = op.create(next)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tm.AutoArchive {
+		return op.archive(todo)
+	}
+
+	return nil
 }
 
 // Resume changes the completion status of a todo and invalidates CompletionDate
 func (tm *TodoManager) Resume(todoID int) error {
-	todo, err := tm.Storage.Get(todoID)
+	return singleResult(tm.ResumeAll([]int{todoID}))
+}
+
+// ResumeAll marks every Todo identified by todoIDs as incomplete in a single batch
+func (tm *TodoManager) ResumeAll(todoIDs []int) ([]BulkResult, error) {
+	return tm.modifyAll("resume", todoIDs, func(todo *Todo) error {
+		todo.Complete = false
+		todo.CompletionDate = InvalidTime
+		return nil
+	})
+}
+
+// Snooze hides a Todo by moving its threshold date forward by an amount such as 3d or 1w. See
+// Recurrence for the units. A threshold date in the past is moved forward from today instead.
+func (tm *TodoManager) Snooze(todoID int, amount string) error {
+	rec, err := ParseRecurrence(amount)
+	if err != nil || rec.Strict {
+		return fmt.Errorf("Invalid snooze amount \"%s\", use a number and unit like 3d or 1w", amount)
+	}
+
+	return tm.modify("snooze", todoID, func(todo *Todo) error {
+		from := dateOf(time.Now())
+		if todo.isFuture(from) {
+			from = todo.ThresholdDate.Time
+		}
+		todo.setAttribute("t", rec.Next(from).Format(TimeFormat))
+		return nil
+	})
+}
+
+// Archive moves every completed Todo from storage to the archive and returns how many moved
+func (tm *TodoManager) Archive() (int, error) {
+	archived := 0
+
+	err := tm.batch("archive", func(op *operation) error {
+		items, err := op.list()
+		if err != nil {
+			return err
+		}
+
+		for _, todo := range items {
+			if !todo.Complete {
+				continue
+			}
+
+			err = op.archive(todo)
+			if err != nil {
+				return err
+			}
+			archived++
+		}
+
+		return nil
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
-	todo.Complete = false
-	todo.CompletionDate = InvalidTime
 
-	return tm.Storage.Update(todoID, todo)
+	return archived, nil
 }
 
 // Delete drops the item specified by todoId from a TodoManager
 func (tm *TodoManager) Delete(todoID int) error {
-	return tm.Storage.Delete(todoID)
+	return singleResult(tm.DeleteAll([]int{todoID}))
+}
+
+// DeleteAll drops every Todo identified by todoIDs in a single batch
+func (tm *TodoManager) DeleteAll(todoIDs []int) ([]BulkResult, error) {
+	return tm.bulk("remove", todoIDs, func(op *operation, todoID int) error {
+		return op.delete(todoID)
+	})
 }
 
 // ListProjects returns a list of unique projects
@@ -275,6 +646,7 @@ func (tm *TodoManager) ListProjects() ([]string, error) {
 	for key := range set {
 		projs = append(projs, key)
 	}
+	sort.Strings(projs)
 
 	return projs, nil
 }
@@ -299,6 +671,7 @@ func (tm *TodoManager) ListContexts() ([]string, error) {
 	for key := range set {
 		ctxs = append(ctxs, key)
 	}
+	sort.Strings(ctxs)
 
 	return ctxs, nil
 }
@@ -323,6 +696,7 @@ func (tm *TodoManager) ListAttributes() ([]string, error) {
 	for key := range set {
 		attrs = append(attrs, key)
 	}
+	sort.Strings(attrs)
 
 	return attrs, nil
 }
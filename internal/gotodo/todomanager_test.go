@@ -1,6 +1,9 @@
 package gotodo
 
 import (
+	"errors"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -32,6 +35,58 @@ func getTestTodoManager() *TodoManager {
 	)
 }
 
+// getTestSeededManager returns a TodoManager holding a todo for each of lines. Unless opts pick
+// other storage, like withTestBolt, lines are written as they are to a todo.txt file in a
+// temporary directory, which is returned too.
+func getTestSeededManager(t *testing.T, lines []string, opts ...TodoManagerOptions) (*TodoManager, *FileStorage) {
+	chosen := &TodoManager{}
+	for _, opt := range opts {
+		opt(chosen)
+	}
+
+	var storage *FileStorage
+	if chosen.Storage == nil {
+		storage = getTestFileStorage(t, strings.Join(lines, "\n"))
+		opts = append([]TodoManagerOptions{WithFileStorage(storage.Path)}, opts...)
+	}
+
+	todoManager := NewTodoManager(opts...)
+	t.Cleanup(func() { todoManager.Close() })
+
+	if storage == nil {
+		for _, line := range lines {
+			assert.NoError(t, todoManager.Storage.Create(FromString(line)))
+		}
+	}
+
+	return todoManager, storage
+}
+
+// withTestBolt stores todos in a bucket of a temporary Bolt database, with its archive next to it
+func withTestBolt(t *testing.T, bucket string) TodoManagerOptions {
+	storage := getTestBoltStorage(t, bucket)
+
+	return func(tm *TodoManager) {
+		tm.Storage = storage
+		tm.ArchiveStorage = &BoltStorage{Bucket: []byte(bucket + archiveBucketSuffix), Path: storage.Path}
+	}
+}
+
+// withTestJournal keeps a journal next to the storage chosen by the options before it
+func withTestJournal(limit int) TodoManagerOptions {
+	return func(tm *TodoManager) {
+		var path string
+		switch storage := tm.Storage.(type) {
+		case *FileStorage:
+			path = storage.Path
+		case *BoltStorage:
+			path = storage.Path
+		}
+
+		tm.Journal = &Journal{Path: filepath.Join(filepath.Dir(path), "journal.json"), Limit: limit}
+	}
+}
+
 func sliceContains(needle string, haystack []string) bool {
 	for _, elem := range haystack {
 		if needle == elem {
@@ -49,7 +104,7 @@ func TestList(t *testing.T) {
 	project := ""
 	context := ""
 	attribute := ""
-	listFilter := TodoListFilter{status, project, context, attribute}
+	listFilter := TodoListFilter{Status: status, Project: project, Context: context, Attribute: attribute}
 
 	items, err := todoManager.List(listFilter)
 	assert.NoError(t, err)
@@ -64,7 +119,7 @@ func TestListAll(t *testing.T) {
 	project := ""
 	context := ""
 	attribute := ""
-	listFilter := TodoListFilter{status, project, context, attribute}
+	listFilter := TodoListFilter{Status: status, Project: project, Context: context, Attribute: attribute}
 
 	items, err := todoManager.List(listFilter)
 	assert.NoError(t, err)
@@ -80,7 +135,7 @@ func TestListDone(t *testing.T) {
 	project := ""
 	context := ""
 	attribute := ""
-	listFilter := TodoListFilter{status, project, context, attribute}
+	listFilter := TodoListFilter{Status: status, Project: project, Context: context, Attribute: attribute}
 
 	items, err := todoManager.List(listFilter)
 	assert.NoError(t, err)
@@ -95,7 +150,7 @@ func TestListProjectFilter(t *testing.T) {
 	project := "gotodo"
 	context := ""
 	attribute := ""
-	listFilter := TodoListFilter{status, project, context, attribute}
+	listFilter := TodoListFilter{Status: status, Project: project, Context: context, Attribute: attribute}
 
 	items, err := todoManager.List(listFilter)
 	assert.NoError(t, err)
@@ -111,7 +166,7 @@ func TestListContextFilter(t *testing.T) {
 	project := ""
 	context := "codehealth"
 	attribute := ""
-	listFilter := TodoListFilter{status, project, context, attribute}
+	listFilter := TodoListFilter{Status: status, Project: project, Context: context, Attribute: attribute}
 
 	items, err := todoManager.List(listFilter)
 	assert.NoError(t, err)
@@ -126,7 +181,7 @@ func TestListAttributeFilter(t *testing.T) {
 	project := ""
 	context := ""
 	attribute := "due"
-	listFilter := TodoListFilter{status, project, context, attribute}
+	listFilter := TodoListFilter{Status: status, Project: project, Context: context, Attribute: attribute}
 
 	items, err := todoManager.List(listFilter)
 	assert.NoError(t, err)
@@ -141,7 +196,7 @@ func TestListCombinedFilter(t *testing.T) {
 	project := ""
 	context := "codehealth"
 	attribute := "due"
-	listFilter := TodoListFilter{status, project, context, attribute}
+	listFilter := TodoListFilter{Status: status, Project: project, Context: context, Attribute: attribute}
 
 	items, err := todoManager.List(listFilter)
 	assert.NoError(t, err)
@@ -441,3 +496,263 @@ func TestListAttributes(t *testing.T) {
 	assert.Equal(t, len(items), 1)
 	assert.Equal(t, sliceContains("due", items), true)
 }
+
+func getTestArchiveManager(t *testing.T, opts ...TodoManagerOptions) *TodoManager {
+	todoManager, _ := getTestSeededManager(t, []string{
+		"(B) 2020-04-28 Work on unit tests @codehealth +gotodo",
+		"x 2020-04-29 2020-04-28 Add parser test +gotodo due:2020-05-01",
+		"x 2020-04-30 2020-04-28 Add storage test +gotodo",
+	}, opts...)
+
+	return todoManager
+}
+
+func TestArchive(t *testing.T) {
+	todoManager := getTestArchiveManager(t)
+
+	archived, err := todoManager.Archive()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, archived)
+
+	items, err := todoManager.List(TodoListFilter{Status: ListAll})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "Work on unit tests @codehealth +gotodo", items[0].Description)
+
+	items, err = todoManager.List(TodoListFilter{Status: ListArchived})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, "Add parser test +gotodo due:2020-05-01", items[0].Description)
+	assert.Equal(t, "2020-04-29", items[0].CompletionDate.Display())
+	assert.Equal(t, "Add storage test +gotodo", items[1].Description)
+}
+
+// unsavableStorage is a FileStorage whose batches fail to save, like a todo.txt on a full disk
+type unsavableStorage struct {
+	*FileStorage
+}
+
+func (me unsavableStorage) Batch(fn func(tx Storage) error) error {
+	return me.FileStorage.Batch(func(tx Storage) error {
+		err := fn(tx)
+		if err != nil {
+			return err
+		}
+
+		return errors.New("No space left on device")
+	})
+}
+
+func TestArchiveSavesStorageFirst(t *testing.T) {
+	todoManager := getTestArchiveManager(t)
+	storage := todoManager.Storage.(*FileStorage)
+	todoManager.Storage = unsavableStorage{storage}
+	before := readTestFile(t, storage)
+
+	// The archive is saved last, so todos aren't archived when todo.txt can't be saved
+	_, err := todoManager.Archive()
+	assert.EqualError(t, err, "No space left on device")
+	assert.Equal(t, before, readTestFile(t, storage))
+
+	items, err := todoManager.List(TodoListFilter{Status: ListArchived})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+}
+
+func TestListArchivedFilter(t *testing.T) {
+	todoManager := getTestArchiveManager(t)
+
+	_, err := todoManager.Archive()
+	assert.NoError(t, err)
+
+	items, err := todoManager.List(TodoListFilter{Status: ListArchived, Attribute: "due"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "Add parser test +gotodo due:2020-05-01", items[0].Description)
+}
+
+func TestCompleteAutoArchive(t *testing.T) {
+	todoManager := getTestArchiveManager(t, WithAutoArchive(true))
+
+	assert.NoError(t, todoManager.Complete(1))
+
+	_, err := todoManager.Storage.Get(1)
+	assert.Equal(t, ErrNotFound, err)
+
+	items, err := todoManager.List(TodoListFilter{Status: ListArchived})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, true, items[0].Complete)
+	assert.Equal(t, "Work on unit tests @codehealth +gotodo", items[0].Description)
+}
+
+func TestEffectivePriority(t *testing.T) {
+	todoManager := NewTodoManager(withTestStorage(), WithDuePrioritization(2))
+	now, err := time.Parse(TimeFormat, "2020-05-01")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, todoManager.effectivePriority(FromString("No due date"), now))
+	assert.Equal(t, 1, todoManager.effectivePriority(FromString("Overdue due:2020-04-30"), now))
+	assert.Equal(t, 1, todoManager.effectivePriority(FromString("(C) Overdue due:2020-04-30"), now))
+	assert.Equal(t, 1, todoManager.effectivePriority(FromString("Due today due:2020-05-01"), now))
+	assert.Equal(t, 1, todoManager.effectivePriority(FromString("Due tomorrow due:2020-05-02"), now))
+	assert.Equal(t, 2, todoManager.effectivePriority(FromString("Due in 2 days due:2020-05-03"), now))
+	assert.Equal(t, 4, todoManager.effectivePriority(FromString("Due in 7 days due:2020-05-08"), now))
+	assert.Equal(t, 3, todoManager.effectivePriority(FromString("(C) Due in 7 days due:2020-05-08"), now))
+	assert.Equal(t, 0, todoManager.effectivePriority(FromString("Due in a year due:2021-05-01"), now))
+	assert.Equal(t, 0, todoManager.effectivePriority(FromString("x Completed due:2020-04-30"), now))
+}
+
+func TestEffectivePriorityOverdue(t *testing.T) {
+	todoManager := NewTodoManager(withTestStorage(), WithDuePrioritization(2))
+	now, err := time.Parse(TimeFormat, "2020-05-01")
+	assert.NoError(t, err)
+
+	overdue := FromString("Overdue due:2020-04-30")
+	overdue.TodoID = 2
+	dueSoon := FromString("(A) Due tomorrow due:2020-05-02")
+	dueSoon.TodoID = 1
+
+	// Both are escalated to priority A, but only one of them is late
+	assert.Equal(t, 1, todoManager.effectivePriority(overdue, now))
+	assert.Equal(t, 1, todoManager.effectivePriority(dueSoon, now))
+	assert.True(t, todoManager.overdue(overdue, now))
+	assert.False(t, todoManager.overdue(dueSoon, now))
+	assert.False(t, todoManager.overdue(FromString("Due today due:2020-05-01"), now))
+	assert.False(t, todoManager.overdue(FromString("x Completed due:2020-04-30"), now))
+
+	todos := TodoList{dueSoon, overdue}
+	sort.Sort(ByEffectivePriority{
+		Todos:    todos,
+		Priority: func(todo *Todo) int { return todoManager.effectivePriority(todo, now) },
+		Overdue:  func(todo *Todo) bool { return todoManager.overdue(todo, now) },
+	})
+	assert.Equal(t, 2, todos[0].TodoID)
+	assert.Equal(t, 1, todos[1].TodoID)
+}
+
+func TestEffectivePriorityDisabled(t *testing.T) {
+	todoManager := getTestTodoManager()
+	now, err := time.Parse(TimeFormat, "2020-05-01")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, todoManager.effectivePriority(FromString("Overdue due:2020-04-30"), now))
+	assert.Equal(t, 3, todoManager.effectivePriority(FromString("(C) Overdue due:2020-04-30"), now))
+}
+
+func TestEscalate(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(TimeFormat)
+	storage := getTestFileStorage(t, strings.Join([]string{
+		"(C) Work on unit tests due:" + tomorrow,
+		"(A) Add parser test",
+		"Add storage test due:" + tomorrow,
+		"x Add sorting test due:" + tomorrow,
+	}, "\n"))
+	todoManager := NewTodoManager(WithFileStorage(storage.Path), WithDuePrioritization(1))
+
+	escalated, err := todoManager.Escalate()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, escalated)
+
+	items, err := todoManager.List(TodoListFilter{Status: ListAll})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, items[0].Priority)
+	assert.Equal(t, 1, items[1].Priority)
+	assert.Equal(t, 2, items[2].Priority)
+	assert.Equal(t, 0, items[3].Priority)
+}
+
+func TestCompleteRecurring(t *testing.T) {
+	storage := getTestFileStorage(t, "Water plants +home rec:1w due:2020-05-01\n")
+	todoManager := NewTodoManager(WithFileStorage(storage.Path))
+	nextDue := time.Now().AddDate(0, 0, 7).Format(TimeFormat)
+
+	assert.NoError(t, todoManager.Complete(1))
+
+	items, err := todoManager.List(TodoListFilter{Status: ListAll})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, true, items[0].Complete)
+	assert.Equal(t, "Water plants +home rec:1w due:2020-05-01", items[0].Description)
+	assert.Equal(t, false, items[1].Complete)
+	assert.Equal(t, "Water plants +home rec:1w due:"+nextDue, items[1].Description)
+	assert.Equal(t, nextDue, items[1].DueDate.Display())
+}
+
+func TestListThreshold(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(TimeFormat)
+	storage := getTestFileStorage(t, strings.Join([]string{
+		"Work on unit tests t:2020-04-28",
+		"Add parser test t:" + tomorrow,
+		"x Add storage test t:" + tomorrow,
+	}, "\n"))
+	todoManager := NewTodoManager(WithFileStorage(storage.Path))
+
+	items, err := todoManager.List(TodoListFilter{Status: ListAll})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, "Work on unit tests t:2020-04-28", items[0].Description)
+	assert.Equal(t, "Add storage test t:"+tomorrow, items[1].Description)
+
+	items, err = todoManager.List(TodoListFilter{Status: ListAll, ShowFuture: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(items))
+}
+
+func TestSnooze(t *testing.T) {
+	today := time.Now()
+	future := today.AddDate(0, 0, 10).Format(TimeFormat)
+	storage := getTestFileStorage(t, strings.Join([]string{
+		"Work on unit tests",
+		"Add parser test t:2020-04-28",
+		"Add storage test t:" + future,
+	}, "\n"))
+	todoManager := NewTodoManager(WithFileStorage(storage.Path))
+
+	assert.NoError(t, todoManager.Snooze(1, "3d"))
+	assert.NoError(t, todoManager.Snooze(2, "1w"))
+	assert.NoError(t, todoManager.Snooze(3, "2d"))
+	assert.Error(t, todoManager.Snooze(1, "later"))
+	assert.Error(t, todoManager.Snooze(1, "+1d"))
+
+	items, err := todoManager.List(TodoListFilter{Status: ListAll, ShowFuture: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Work on unit tests t:"+today.AddDate(0, 0, 3).Format(TimeFormat), items[0].Description)
+	assert.Equal(t, "Add parser test t:"+today.AddDate(0, 0, 7).Format(TimeFormat), items[1].Description)
+	assert.Equal(t, "Add storage test t:"+today.AddDate(0, 0, 12).Format(TimeFormat), items[2].Description)
+	assert.Equal(t, today.AddDate(0, 0, 12).Format(TimeFormat), items[2].ThresholdDate.Display())
+}
+
+func TestUpdateKeepsLine(t *testing.T) {
+	storage := getTestFileStorage(t, "(B) Work on unit tests\n")
+	todoManager := NewTodoManager(WithFileStorage(storage.Path))
+
+	todoStr := "(A)  Work on unit tests   due:2020-05-01 t:2020-04-30"
+	assert.NoError(t, todoManager.Update(1, todoStr))
+	assert.Equal(t, todoStr+"\n", readTestFile(t, storage))
+
+	todo, err := storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-05-01", todo.DueDate.Display())
+	assert.Equal(t, "2020-04-30", todo.ThresholdDate.Display())
+}
+
+func TestMemoryStorageManager(t *testing.T) {
+	todoManager := NewTodoManager(WithMemoryStorage())
+	todoManager.AutoArchive = true
+
+	todoID, err := todoManager.Add("Work on unit tests")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, todoID)
+	assert.NoError(t, todoManager.Complete(todoID))
+
+	// the archived todo's ID isn't reused
+	todoID, err = todoManager.Add("Add parser test")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, todoID)
+
+	items, err := todoManager.ArchiveStorage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "Work on unit tests", items[0].Description)
+}
@@ -0,0 +1,527 @@
+// Package server exposes a TodoManager over a JSON REST API
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+)
+
+// maxBodySize limits the size of a request body
+const maxBodySize = 1 << 20
+
+// Server is an http.Handler serving the todos of a TodoManager:
+//
+//	GET    /todos                  list todos, filtered like the list command
+//	POST   /todos                  create a todo from a todo.txt line or JSON
+//	GET    /todos/{id}             get a todo
+//	PATCH  /todos/{id}             change the text, priority or completion of a todo
+//	DELETE /todos/{id}             remove a todo
+//	POST   /todos/{id}/complete    mark a todo as complete
+//	POST   /todos/{id}/resume      mark a todo as incomplete
+//	POST   /todos/{id}/prioritize  set the priority of a todo, or remove it when empty
+type Server struct {
+	manager *gotodo.TodoManager
+	token   string
+
+	// mu serializes requests, as a TodoManager isn't safe for concurrent use
+	mu sync.Mutex
+}
+
+// ServerOptions provides functional options to Server
+type ServerOptions func(*Server)
+
+// WithToken requires every request to send the token as an "Authorization: Bearer" header
+func WithToken(token string) ServerOptions {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// New builds a Server for a TodoManager with options
+func New(tm *gotodo.TodoManager, opts ...ServerOptions) *Server {
+	s := &Server{manager: tm}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// httpError is an error with the status code to respond with
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+// errorf builds an httpError with a formatted message
+func errorf(status int, format string, args ...interface{}) error {
+	return &httpError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// errorBody is the JSON response describing an error
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// todoInput is the JSON body that creates a todo. Text is a complete todo.txt line; without it
+// the line is built from the other fields.
+type todoInput struct {
+	Text        string   `json:"text"`
+	Priority    string   `json:"priority"`
+	Description string   `json:"description"`
+	Projects    []string `json:"projects"`
+	Contexts    []string `json:"contexts"`
+	DueDate     string   `json:"due_date"`
+}
+
+// todoPatch is the JSON body that changes a todo. Fields left out are not changed.
+type todoPatch struct {
+	Text     *string `json:"text"`
+	Priority *string `json:"priority"`
+	Complete *bool   `json:"complete"`
+}
+
+// priorityInput is the JSON body that sets the priority of a todo
+type priorityInput struct {
+	Priority string `json:"priority"`
+}
+
+// ServeHTTP checks the token, routes the request and writes the response
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, errorf(http.StatusUnauthorized, "Missing or invalid bearer token"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Release the storage after every request, so the gotodo CLI can use a Bolt database while
+	// the server is idle
+	defer s.manager.Close()
+
+	status, body, err := s.route(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, status, body)
+}
+
+// authorized checks the bearer token of a request
+func (s *Server) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+
+	token := strings.TrimPrefix(header, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// route runs the handler for the path and method of a request. It returns the status and body
+// of the response, where a nil body is sent as No Content.
+func (s *Server) route(r *http.Request) (int, interface{}, error) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "todos" || len(parts) > 3 {
+		return 0, nil, errorf(http.StatusNotFound, "No such endpoint %s", r.URL.Path)
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			return s.list(r)
+		case http.MethodPost:
+			return s.create(r)
+		}
+		return 0, nil, methodNotAllowed(r, http.MethodGet, http.MethodPost)
+	}
+
+	todoID, err := strconv.Atoi(parts[1])
+	if err != nil || todoID < 1 {
+		return 0, nil, errorf(http.StatusNotFound, "Invalid todo ID %s", parts[1])
+	}
+
+	if len(parts) == 2 {
+		switch r.Method {
+		case http.MethodGet:
+			return s.get(todoID)
+		case http.MethodPatch:
+			return s.patch(r, todoID)
+		case http.MethodDelete:
+			return s.delete(todoID)
+		}
+		return 0, nil, methodNotAllowed(r, http.MethodGet, http.MethodPatch, http.MethodDelete)
+	}
+
+	var action func() error
+	switch parts[2] {
+	case "complete":
+		action = func() error { return s.manager.Complete(todoID) }
+	case "resume":
+		action = func() error { return s.manager.Resume(todoID) }
+	case "prioritize":
+		action = func() error { return s.prioritize(r, todoID) }
+	default:
+		return 0, nil, errorf(http.StatusNotFound, "No such endpoint %s", r.URL.Path)
+	}
+
+	if r.Method != http.MethodPost {
+		return 0, nil, methodNotAllowed(r, http.MethodPost)
+	}
+
+	if err := action(); err != nil {
+		return 0, nil, err
+	}
+
+	return s.changed(todoID)
+}
+
+// methodNotAllowed builds the error for a method an endpoint doesn't support
+func methodNotAllowed(r *http.Request, allowed ...string) error {
+	return &httpError{
+		status:  http.StatusMethodNotAllowed,
+		message: fmt.Sprintf("Method %s is not allowed, use %s", r.Method, strings.Join(allowed, " or ")),
+	}
+}
+
+// list returns the todos matching the query parameters, which mirror the flags of the list command
+func (s *Server) list(r *http.Request) (int, interface{}, error) {
+	params := r.URL.Query()
+
+	status := gotodo.ListPending
+	switch params.Get("status") {
+	case "", "pending":
+	case "all":
+		status = gotodo.ListAll
+	case "done":
+		status = gotodo.ListDone
+	case "archived":
+		status = gotodo.ListArchived
+	default:
+		return 0, nil, errorf(http.StatusBadRequest, "Unsupported status \"%s\", use pending, all, done or archived", params.Get("status"))
+	}
+
+	showFuture, err := boolParam(params, "show_future")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	showBlocked, err := boolParam(params, "show_blocked")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query := params.Get("query")
+	if query != "" {
+		if _, err := gotodo.ParseQuery(query); err != nil {
+			return 0, nil, errorf(http.StatusBadRequest, "%s", err)
+		}
+	}
+
+	items, err := s.manager.List(gotodo.TodoListFilter{
+		Status:      status,
+		Project:     params.Get("project"),
+		Context:     params.Get("context"),
+		Attribute:   params.Get("attribute"),
+		Query:       query,
+		ShowFuture:  showFuture,
+		ShowBlocked: showBlocked,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	switch params.Get("sort") {
+	case "", "pending", "priority":
+		sort.Sort(gotodo.ByEffectivePriority{
+			Todos:    items,
+			Priority: s.manager.EffectivePriority,
+			Overdue:  s.manager.Overdue,
+		})
+	case "due":
+		sort.Sort(gotodo.ByDueDate(items))
+	case "created":
+		sort.Sort(gotodo.ByCreatedDate(items))
+	default:
+		return 0, nil, errorf(http.StatusBadRequest, "Unsupported sort \"%s\", use priority, due or created", params.Get("sort"))
+	}
+
+	return http.StatusOK, items, nil
+}
+
+// boolParam parses an optional true or false query parameter
+func boolParam(params url.Values, name string) (bool, error) {
+	value := params.Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errorf(http.StatusBadRequest, "Invalid %s \"%s\", use true or false", name, value)
+	}
+
+	return parsed, nil
+}
+
+// get returns a single todo
+func (s *Server) get(todoID int) (int, interface{}, error) {
+	todo, err := s.manager.Storage.Get(todoID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, todo, nil
+}
+
+// changed returns a todo after it was changed, or No Content when it was archived
+func (s *Server) changed(todoID int) (int, interface{}, error) {
+	todo, err := s.manager.Storage.Get(todoID)
+	if errors.Is(err, gotodo.ErrNotFound) {
+		return http.StatusNoContent, nil, nil
+	} else if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, todo, nil
+}
+
+// create adds a todo from a text/plain todo.txt line or a JSON todoInput
+func (s *Server) create(r *http.Request) (int, interface{}, error) {
+	var line string
+
+	if isJSON(r) {
+		var input todoInput
+		if err := decodeJSON(r, &input); err != nil {
+			return 0, nil, err
+		}
+
+		var err error
+		line, err = input.line()
+		if err != nil {
+			return 0, nil, err
+		}
+	} else {
+		body, err := readBody(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		line = strings.TrimSpace(string(body))
+	}
+
+	if line == "" {
+		return 0, nil, errorf(http.StatusBadRequest, "Todo is empty")
+	} else if strings.ContainsAny(line, "\r\n") {
+		return 0, nil, errorf(http.StatusBadRequest, "Todo must be a single line")
+	}
+	if err := gotodo.ValidateTodo(line); err != nil {
+		return 0, nil, errorf(http.StatusBadRequest, "%s", err)
+	}
+
+	todoID, err := s.manager.Add(line)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	todo, err := s.manager.Storage.Get(todoID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, todo, nil
+}
+
+// line builds the todo.txt line described by a todoInput
+func (me todoInput) line() (string, error) {
+	if me.Text != "" {
+		return me.Text, nil
+	}
+
+	if me.Description == "" {
+		return "", errorf(http.StatusBadRequest, "Todo needs a text or description")
+	}
+
+	parts := make([]string, 0)
+	if me.Priority != "" {
+		if !gotodo.IsPriorityString(me.Priority) {
+			return "", errorf(http.StatusBadRequest, "Invalid priority value: %s", me.Priority)
+		}
+		parts = append(parts, fmt.Sprintf("(%s)", strings.ToUpper(me.Priority)))
+	}
+
+	parts = append(parts, me.Description)
+	for _, project := range me.Projects {
+		parts = append(parts, "+"+project)
+	}
+	for _, context := range me.Contexts {
+		parts = append(parts, "@"+context)
+	}
+
+	// The due date is checked along with the rest of the line by create
+	if me.DueDate != "" {
+		parts = append(parts, "due:"+me.DueDate)
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// patch changes the text, priority and completion of a todo, in that order, as a single change
+func (s *Server) patch(r *http.Request, todoID int) (int, interface{}, error) {
+	var input todoPatch
+	if err := decodeJSON(r, &input); err != nil {
+		return 0, nil, err
+	}
+
+	if input.Text == nil && input.Priority == nil && input.Complete == nil {
+		return 0, nil, errorf(http.StatusBadRequest, "Nothing to change, send a text, priority or complete")
+	}
+
+	patch := gotodo.TodoPatch{Priority: input.Priority, Complete: input.Complete}
+	if input.Text != nil {
+		text := strings.TrimSpace(*input.Text)
+		if text == "" || strings.ContainsAny(text, "\r\n") {
+			return 0, nil, errorf(http.StatusBadRequest, "Todo must be a single, non-empty line")
+		}
+		if err := gotodo.ValidateTodo(text); err != nil {
+			return 0, nil, errorf(http.StatusBadRequest, "%s", err)
+		}
+		patch.Text = &text
+	}
+
+	if input.Priority != nil && *input.Priority != "" && !gotodo.IsPriorityString(*input.Priority) {
+		return 0, nil, errorf(http.StatusBadRequest, "Invalid priority value: %s", *input.Priority)
+	}
+
+	// Every field is changed in one operation, so a failure changes nothing and undo reverts it all
+	if err := s.manager.Patch(todoID, patch); err != nil {
+		return 0, nil, err
+	}
+
+	return s.changed(todoID)
+}
+
+// prioritize sets the priority of a todo from a JSON priorityInput or a priority query parameter
+func (s *Server) prioritize(r *http.Request, todoID int) error {
+	priority := r.URL.Query().Get("priority")
+
+	if isJSON(r) {
+		var input priorityInput
+		if err := decodeJSON(r, &input); err != nil {
+			return err
+		}
+		priority = input.Priority
+	}
+
+	return s.setPriority(todoID, priority)
+}
+
+// setPriority sets the priority of a todo, removing it when priority is empty
+func (s *Server) setPriority(todoID int, priority string) error {
+	if priority == "" {
+		return s.manager.Deprioritize(todoID)
+	}
+
+	if !gotodo.IsPriorityString(priority) {
+		return errorf(http.StatusBadRequest, "Invalid priority value: %s", priority)
+	}
+
+	return s.manager.Prioritize(todoID, priority)
+}
+
+// delete removes a todo
+func (s *Server) delete(todoID int) (int, interface{}, error) {
+	if err := s.manager.Delete(todoID); err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// isJSON checks whether a request has a JSON body
+func isJSON(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/json"
+}
+
+// readBody reads the body of a request, up to maxBodySize
+func readBody(r *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "Can't read request body: %s", err)
+	} else if len(body) > maxBodySize {
+		return nil, errorf(http.StatusRequestEntityTooLarge, "Request body is larger than %d bytes", maxBodySize)
+	}
+
+	return body, nil
+}
+
+// decodeJSON decodes the JSON body of a request into value, rejecting unknown fields
+func decodeJSON(r *http.Request, value interface{}) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return errorf(http.StatusBadRequest, "Invalid JSON body: %s", err)
+	}
+
+	return nil
+}
+
+// writeJSON writes a JSON response, or an empty one for No Content
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	if status == http.StatusNoContent || body == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error response, mapping errors from gotodo to status codes
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	var httpErr *httpError
+	var cycleErr *gotodo.CycleError
+	if errors.As(err, &httpErr) {
+		status = httpErr.status
+	} else if errors.As(err, &cycleErr) {
+		status = http.StatusConflict
+	} else if errors.Is(err, gotodo.ErrNotFound) {
+		status = http.StatusNotFound
+	} else if errors.Is(err, gotodo.ErrBulkFailed) {
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorBody{Error: err.Error()})
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestServer(t *testing.T, opts ...ServerOptions) (*Server, string) {
+	dir, err := ioutil.TempDir("", "gotodo")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "todo.txt")
+	contents := strings.Join([]string{
+		"(B) 2020-04-28 Work on unit tests @codehealth +gotodo",
+		"Add parser test +gotodo due:2020-06-01",
+		"x 2020-05-02 Write README",
+	}, "\n")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+
+	return New(gotodo.NewTodoManager(gotodo.WithFileStorage(path)), opts...), path
+}
+
+// request sends a request to the server and decodes its JSON response into result
+func request(t *testing.T, s *Server, method string, target string, contentType string, body string, result interface{}) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if result != nil {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), result))
+	}
+
+	return w
+}
+
+// todoBody holds the fields of a todo in a response that the tests check
+type todoBody struct {
+	ID       int    `json:"id"`
+	Complete bool   `json:"complete"`
+	Priority string `json:"priority"`
+	Text     string `json:"text"`
+}
+
+func TestServerList(t *testing.T) {
+	s, _ := getTestServer(t)
+
+	var todos []todoBody
+	w := request(t, s, "GET", "/todos", "", "", &todos)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, []todoBody{
+		{ID: 1, Priority: "B", Text: "(B) 2020-04-28 Work on unit tests @codehealth +gotodo"},
+		{ID: 2, Text: "Add parser test +gotodo due:2020-06-01"},
+	}, todos)
+
+	request(t, s, "GET", "/todos?status=all&query=due<2020-07-01", "", "", &todos)
+	assert.Equal(t, 1, len(todos))
+	assert.Equal(t, 2, todos[0].ID)
+
+	request(t, s, "GET", "/todos?status=done", "", "", &todos)
+	assert.Equal(t, 1, len(todos))
+	assert.Equal(t, true, todos[0].Complete)
+
+	request(t, s, "GET", "/todos?context=codehealth", "", "", &todos)
+	assert.Equal(t, 1, len(todos))
+
+	var errBody errorBody
+	w = request(t, s, "GET", "/todos?query=due<soon", "", "", &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(t, s, "GET", "/todos?status=someday", "", "", &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Unsupported status \"someday\", use pending, all, done or archived", errBody.Error)
+}
+
+func TestServerGet(t *testing.T) {
+	s, _ := getTestServer(t)
+
+	var todo todoBody
+	w := request(t, s, "GET", "/todos/2", "", "", &todo)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Add parser test +gotodo due:2020-06-01", todo.Text)
+
+	var errBody errorBody
+	w = request(t, s, "GET", "/todos/9", "", "", &errBody)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Todo ID does not exist", errBody.Error)
+
+	w = request(t, s, "GET", "/todos/abc", "", "", &errBody)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(t, s, "GET", "/projects", "", "", &errBody)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(t, s, "PUT", "/todos/2", "", "", &errBody)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "Method PUT is not allowed, use GET or PATCH or DELETE", errBody.Error)
+}
+
+func TestServerCreate(t *testing.T) {
+	s, path := getTestServer(t)
+
+	var todo todoBody
+	w := request(t, s, "POST", "/todos", "text/plain", "(A) Ship release +gotodo\n", &todo)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, todoBody{ID: 4, Priority: "A", Text: "(A) Ship release +gotodo"}, todo)
+
+	w = request(t, s, "POST", "/todos", "application/json", `{"text": "Plan sprint @work"}`, &todo)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "Plan sprint @work", todo.Text)
+
+	body := `{"description": "Review PR", "priority": "c", "projects": ["gotodo"], "contexts": ["work"], "due_date": "2020-06-05"}`
+	w = request(t, s, "POST", "/todos", "application/json; charset=utf-8", body, &todo)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, todoBody{ID: 6, Priority: "C", Text: "(C) Review PR +gotodo @work due:2020-06-05"}, todo)
+
+	contents, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, true, strings.HasSuffix(string(contents), "(A) Ship release +gotodo\nPlan sprint @work\n(C) Review PR +gotodo @work due:2020-06-05\n"))
+
+	var errBody errorBody
+	w = request(t, s, "POST", "/todos", "text/plain", "  ", &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Todo is empty", errBody.Error)
+
+	w = request(t, s, "POST", "/todos", "text/plain", "One\nTwo", &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(t, s, "POST", "/todos", "application/json", `{"title": "Unknown field"}`, &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(t, s, "POST", "/todos", "application/json", `{"description": "Bad date", "due_date": "someday"}`, &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Invalid due date \"someday\", use YYYY-MM-DD or a day like tomorrow, fri or +3d", errBody.Error)
+
+	w = request(t, s, "POST", "/todos", "text/plain", "Water plants t:soon", &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Invalid threshold date \"soon\", use YYYY-MM-DD", errBody.Error)
+}
+
+func TestServerChanges(t *testing.T) {
+	s, _ := getTestServer(t)
+
+	var todo todoBody
+	w := request(t, s, "POST", "/todos/2/prioritize", "application/json", `{"priority": "A"}`, &todo)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "(A) Add parser test +gotodo due:2020-06-01", todo.Text)
+
+	w = request(t, s, "POST", "/todos/2/prioritize", "", "", &todo)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", todo.Priority)
+
+	w = request(t, s, "POST", "/todos/2/complete", "", "", &todo)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, todo.Complete)
+
+	w = request(t, s, "POST", "/todos/2/resume", "", "", &todo)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, false, todo.Complete)
+
+	w = request(t, s, "PATCH", "/todos/2", "application/json", `{"text": "Add query test +gotodo", "priority": "B"}`, &todo)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "(B) Add query test +gotodo", todo.Text)
+
+	w = request(t, s, "PATCH", "/todos/2", "application/json", `{"complete": true}`, &todo)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, todo.Complete)
+
+	var errBody errorBody
+	w = request(t, s, "PATCH", "/todos/2", "application/json", `{}`, &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(t, s, "PATCH", "/todos/9", "application/json", `{"priority": "A"}`, &errBody)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// an invalid text changes nothing, not even the valid fields sent with it
+	w = request(t, s, "PATCH", "/todos/1", "application/json", `{"text": "Fix tests rec:often", "priority": "A"}`, &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Invalid recurrence \"often\", use a number and unit like 1w or +3d", errBody.Error)
+	w = request(t, s, "PATCH", "/todos/1", "application/json", `{"text": "Fix tests due:later", "complete": true}`, &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(t, s, "PATCH", "/todos/1", "application/json", `{"priority": "1"}`, &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(t, s, "GET", "/todos/1", "", "", &todo)
+	assert.Equal(t, "(B) 2020-04-28 Work on unit tests @codehealth +gotodo", todo.Text)
+
+	w = request(t, s, "POST", "/todos/1/prioritize?priority=1", "", "", &errBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Invalid priority value: 1", errBody.Error)
+
+	w = request(t, s, "POST", "/todos/9/complete", "", "", &errBody)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(t, s, "GET", "/todos/1/complete", "", "", &errBody)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestServerDelete(t *testing.T) {
+	s, _ := getTestServer(t)
+
+	w := request(t, s, "DELETE", "/todos/1", "", "", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 0, w.Body.Len())
+
+	var errBody errorBody
+	w = request(t, s, "GET", "/todos/1", "", "", &errBody)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(t, s, "DELETE", "/todos/1", "", "", &errBody)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestServerToken(t *testing.T) {
+	s, _ := getTestServer(t, WithToken("secret"))
+
+	var errBody errorBody
+	w := request(t, s, "GET", "/todos", "", "", &errBody)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, "Missing or invalid bearer token", errBody.Error)
+
+	r := httptest.NewRequest("GET", "/todos", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	r = httptest.NewRequest("GET", "/todos", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
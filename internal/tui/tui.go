@@ -0,0 +1,499 @@
+// Package tui is a full-screen terminal interface to a TodoManager
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+// mode is what keystrokes currently do
+type mode int
+
+const (
+	modeList mode = iota
+	modeAdd
+	modeEdit
+	modeFilter
+	modePriority
+)
+
+// prompts are shown in front of the input line of each input mode
+var prompts = map[mode]string{
+	modeAdd:      "Add: ",
+	modeEdit:     "Edit: ",
+	modeFilter:   "Filter: ",
+	modePriority: "Priority: ",
+}
+
+// sortMode is one of the orders the list can be sorted in
+type sortMode struct {
+	name string
+	sort func(items gotodo.TodoList)
+}
+
+// sortModes are cycled through with the s key
+var sortModes = []sortMode{
+	{"priority", func(items gotodo.TodoList) { sort.Sort(gotodo.ByPriority(items)) }},
+	{"due date", func(items gotodo.TodoList) { sort.Sort(gotodo.ByDueDate(items)) }},
+	{"created date", func(items gotodo.TodoList) { sort.Sort(gotodo.ByCreatedDate(items)) }},
+}
+
+// helpText lists the keyboard shortcuts at the bottom of the screen
+const helpText = "x complete  +/- priority  p set priority  a add  e edit  / filter  s sort  c show done  u undo  q quit"
+
+// lowestPriority is the priority a todo without one gets when raised, Z
+const lowestPriority = 26
+
+// app is the state of the interface
+type app struct {
+	tm       *gotodo.TodoManager
+	screen   tcell.Screen
+	items    gotodo.TodoList
+	cursor   int
+	offset   int
+	sortMode int
+	filter   string
+	showDone bool
+	mode     mode
+	input    []rune
+	inputPos int
+	message  string
+	quit     bool
+}
+
+// Run shows the todos of tm full screen until the user quits
+func Run(tm *gotodo.TodoManager) error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+
+	err = screen.Init()
+	if err != nil {
+		return err
+	}
+	defer screen.Fini()
+
+	return run(tm, screen)
+}
+
+// run handles events from an initialized screen until the user quits
+func run(tm *gotodo.TodoManager, screen tcell.Screen) error {
+	a := &app{tm: tm, screen: screen}
+
+	err := a.reload()
+	if err != nil {
+		return err
+	}
+
+	for !a.quit {
+		a.draw()
+
+		switch ev := screen.PollEvent().(type) {
+		case *tcell.EventKey:
+			a.handleKey(ev)
+		case *tcell.EventResize:
+			screen.Sync()
+		case nil:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// selected returns the todo under the cursor, or nil when the list is empty
+func (a *app) selected() *gotodo.Todo {
+	if a.cursor < 0 || a.cursor >= len(a.items) {
+		return nil
+	}
+
+	return a.items[a.cursor]
+}
+
+// reload lists and sorts the todos again, keeping the cursor on the same todo if it is still shown
+func (a *app) reload() error {
+	selectedID := -1
+	if todo := a.selected(); todo != nil {
+		selectedID = todo.TodoID
+	}
+
+	status := gotodo.ListPending
+	if a.showDone {
+		status = gotodo.ListAll
+	}
+
+	items, err := a.tm.List(gotodo.TodoListFilter{Status: status, Query: a.filter})
+	if err != nil {
+		return err
+	}
+	sortModes[a.sortMode].sort(items)
+	a.items = items
+
+	for idx, todo := range items {
+		if todo.TodoID == selectedID {
+			a.cursor = idx
+			return nil
+		}
+	}
+	a.moveCursor(0)
+
+	return nil
+}
+
+// apply reports the outcome of a change and reloads the list
+func (a *app) apply(err error, message string) {
+	if err != nil {
+		a.message = err.Error()
+		return
+	}
+
+	a.message = message
+	if err = a.reload(); err != nil {
+		a.message = err.Error()
+	}
+}
+
+// moveCursor moves the cursor by delta rows, keeping it on the list
+func (a *app) moveCursor(delta int) {
+	a.cursor += delta
+	if a.cursor >= len(a.items) {
+		a.cursor = len(a.items) - 1
+	}
+	if a.cursor < 0 {
+		a.cursor = 0
+	}
+}
+
+// listHeight is the number of rows available to the list, between the title and the status lines
+func (a *app) listHeight() int {
+	_, height := a.screen.Size()
+	if height < 4 {
+		return 1
+	}
+
+	return height - 3
+}
+
+// startInput switches to an input mode with some initial text
+func (a *app) startInput(m mode, text string) {
+	a.mode = m
+	a.input = []rune(text)
+	a.inputPos = len(a.input)
+	a.message = ""
+}
+
+// handleKey dispatches a key press according to the mode
+func (a *app) handleKey(ev *tcell.EventKey) {
+	if ev.Key() == tcell.KeyCtrlC {
+		a.quit = true
+		return
+	}
+
+	if a.mode != modeList {
+		a.handleInputKey(ev)
+		return
+	}
+
+	a.message = ""
+	switch ev.Key() {
+	case tcell.KeyUp:
+		a.moveCursor(-1)
+	case tcell.KeyDown:
+		a.moveCursor(1)
+	case tcell.KeyPgUp:
+		a.moveCursor(-a.listHeight())
+	case tcell.KeyPgDn:
+		a.moveCursor(a.listHeight())
+	case tcell.KeyHome:
+		a.moveCursor(-len(a.items))
+	case tcell.KeyEnd:
+		a.moveCursor(len(a.items))
+	case tcell.KeyEscape:
+		a.filter = ""
+		a.apply(nil, "")
+	case tcell.KeyRune:
+		a.handleListRune(ev.Rune())
+	}
+}
+
+// handleListRune runs the shortcut for a key pressed in the list
+func (a *app) handleListRune(r rune) {
+	todo := a.selected()
+
+	switch r {
+	case 'q':
+		a.quit = true
+	case 'k':
+		a.moveCursor(-1)
+	case 'j':
+		a.moveCursor(1)
+	case 'g':
+		a.moveCursor(-len(a.items))
+	case 'G':
+		a.moveCursor(len(a.items))
+	case 'a':
+		a.startInput(modeAdd, "")
+	case '/':
+		a.startInput(modeFilter, a.filter)
+	case 's':
+		a.sortMode = (a.sortMode + 1) % len(sortModes)
+		a.apply(nil, "Sorted by "+sortModes[a.sortMode].name)
+	case 'c':
+		a.showDone = !a.showDone
+		a.apply(nil, "")
+	case 'u':
+		entries, err := a.tm.Undo(1)
+		if len(entries) > 0 {
+			a.apply(err, "Undid "+entries[0].Operation)
+		} else {
+			a.apply(err, "")
+		}
+	}
+
+	if todo == nil {
+		return
+	}
+
+	switch r {
+	case 'x', ' ':
+		if todo.Complete {
+			a.apply(a.tm.Resume(todo.TodoID), fmt.Sprintf("Resumed Todo ID %d", todo.TodoID))
+		} else {
+			a.apply(a.tm.Complete(todo.TodoID), fmt.Sprintf("Completed Todo ID %d", todo.TodoID))
+		}
+	case '+':
+		a.setPriority(todo, raisePriority(todo.Priority))
+	case '-':
+		a.setPriority(todo, lowerPriority(todo.Priority))
+	case 'p':
+		a.startInput(modePriority, gotodo.PriorityString(todo.Priority))
+	case 'e':
+		a.startInput(modeEdit, todo.String())
+	}
+}
+
+// raisePriority returns the next higher priority. A todo without a priority is raised to Z.
+func raisePriority(priority int) int {
+	if priority == 0 {
+		return lowestPriority
+	} else if priority > 1 {
+		return priority - 1
+	}
+
+	return priority
+}
+
+// lowerPriority returns the next lower priority. Lowering Z removes the priority.
+func lowerPriority(priority int) int {
+	if priority == 0 || priority >= lowestPriority {
+		return 0
+	}
+
+	return priority + 1
+}
+
+// setPriority saves a new priority for a todo
+func (a *app) setPriority(todo *gotodo.Todo, priority int) {
+	if priority == todo.Priority {
+		return
+	}
+
+	if priority == 0 {
+		a.apply(a.tm.Deprioritize(todo.TodoID), fmt.Sprintf("Removed priority for Todo ID %d", todo.TodoID))
+		return
+	}
+
+	letters := gotodo.PriorityString(priority)
+	a.apply(a.tm.Prioritize(todo.TodoID, letters), fmt.Sprintf("Updated priority for Todo ID %d to (%s)", todo.TodoID, letters))
+}
+
+// handleInputKey edits the input line, and submits or cancels it
+func (a *app) handleInputKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		a.mode = modeList
+	case tcell.KeyEnter:
+		m := a.mode
+		a.mode = modeList
+		a.submit(m, string(a.input))
+	case tcell.KeyLeft:
+		if a.inputPos > 0 {
+			a.inputPos--
+		}
+	case tcell.KeyRight:
+		if a.inputPos < len(a.input) {
+			a.inputPos++
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		a.inputPos = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		a.inputPos = len(a.input)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if a.inputPos > 0 {
+			a.input = append(a.input[:a.inputPos-1], a.input[a.inputPos:]...)
+			a.inputPos--
+		}
+	case tcell.KeyDelete:
+		if a.inputPos < len(a.input) {
+			a.input = append(a.input[:a.inputPos], a.input[a.inputPos+1:]...)
+		}
+	case tcell.KeyRune:
+		a.input = append(a.input[:a.inputPos], append([]rune{ev.Rune()}, a.input[a.inputPos:]...)...)
+		a.inputPos++
+	}
+}
+
+// submit acts on the text entered in an input mode
+func (a *app) submit(m mode, text string) {
+	text = strings.TrimSpace(text)
+
+	switch m {
+	case modeFilter:
+		if _, err := gotodo.ParseQuery(text); text != "" && err != nil {
+			a.message = err.Error()
+			return
+		}
+		a.filter = text
+		a.apply(nil, "")
+		return
+	case modeAdd:
+		if text == "" {
+			return
+		}
+		todoID, err := a.tm.Add(text)
+		a.apply(err, fmt.Sprintf("Added Todo ID %d", todoID))
+		a.selectID(todoID)
+		return
+	}
+
+	todo := a.selected()
+	if todo == nil {
+		return
+	}
+
+	switch m {
+	case modeEdit:
+		if text == "" {
+			return
+		}
+		a.apply(a.tm.Update(todo.TodoID, text), fmt.Sprintf("Updated Todo ID %d", todo.TodoID))
+	case modePriority:
+		if text == "" {
+			a.setPriority(todo, 0)
+		} else if !gotodo.IsPriorityString(text) {
+			a.message = fmt.Sprintf("invalid priority value: %s", text)
+		} else {
+			a.apply(a.tm.Prioritize(todo.TodoID, text), fmt.Sprintf("Updated priority for Todo ID %d to (%s)", todo.TodoID, strings.ToUpper(text)))
+		}
+	}
+}
+
+// selectID moves the cursor to a todo, if it is shown
+func (a *app) selectID(todoID int) {
+	for idx, todo := range a.items {
+		if todo.TodoID == todoID {
+			a.cursor = idx
+			return
+		}
+	}
+}
+
+// draw renders the whole screen
+func (a *app) draw() {
+	a.screen.Clear()
+	width, height := a.screen.Size()
+	listHeight := a.listHeight()
+
+	// keep the cursor in view
+	if a.cursor < a.offset {
+		a.offset = a.cursor
+	} else if a.cursor >= a.offset+listHeight {
+		a.offset = a.cursor - listHeight + 1
+	}
+
+	title := fmt.Sprintf(" gotodo  %d todos  sorted by %s", len(a.items), sortModes[a.sortMode].name)
+	if a.filter != "" {
+		title += "  filter: " + a.filter
+	}
+	titleStyle := tcell.StyleDefault.Reverse(true).Bold(true)
+	a.drawText(0, 0, width, padRight(title, width), titleStyle)
+
+	if len(a.items) == 0 {
+		a.drawText(1, 1, width, "No todos to display.", tcell.StyleDefault.Dim(true))
+	}
+
+	for row := 0; row < listHeight && a.offset+row < len(a.items); row++ {
+		idx := a.offset + row
+		todo := a.items[idx]
+
+		style := priorityStyle(todo)
+		if idx == a.cursor {
+			style = style.Reverse(true)
+		}
+
+		line := fmt.Sprintf("%4d %s", todo.TodoID, todo.String())
+		a.drawText(0, row+1, width, padRight(line, width), style)
+	}
+
+	if a.mode != modeList {
+		prompt := prompts[a.mode]
+		a.drawText(0, height-2, width, prompt+string(a.input), tcell.StyleDefault)
+		a.screen.ShowCursor(runewidth.StringWidth(prompt+string(a.input[:a.inputPos])), height-2)
+	} else {
+		a.screen.HideCursor()
+		a.drawText(0, height-2, width, a.message, tcell.StyleDefault.Bold(true))
+	}
+	a.drawText(0, height-1, width, helpText, tcell.StyleDefault.Dim(true))
+
+	a.screen.Show()
+}
+
+// priorityStyle colors a todo by its priority, and dims completed todos
+func priorityStyle(todo *gotodo.Todo) tcell.Style {
+	style := tcell.StyleDefault
+	switch {
+	case todo.Complete:
+		return style.Dim(true)
+	case todo.Priority == 1:
+		return style.Foreground(tcell.ColorRed)
+	case todo.Priority == 2:
+		return style.Foreground(tcell.ColorYellow)
+	case todo.Priority == 3:
+		return style.Foreground(tcell.ColorGreen)
+	}
+
+	return style
+}
+
+// drawText writes text on a row starting at column x, cutting it off at the width of the screen
+func (a *app) drawText(x int, y int, width int, text string, style tcell.Style) {
+	for _, r := range text {
+		if r == '\t' {
+			r = ' '
+		}
+		w := runewidth.RuneWidth(r)
+		if w == 0 {
+			continue
+		}
+		if x+w > width {
+			return
+		}
+		a.screen.SetContent(x, y, r, nil, style)
+		x += w
+	}
+}
+
+// padRight fills text with spaces up to width, so highlighted rows span the screen
+func padRight(text string, width int) string {
+	if gap := width - runewidth.StringWidth(text); gap > 0 {
+		return text + strings.Repeat(" ", gap)
+	}
+
+	return text
+}
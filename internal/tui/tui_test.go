@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dkrichards86/gotodo/internal/gotodo"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestManager(t *testing.T, contents string) (*gotodo.TodoManager, string) {
+	dir, err := ioutil.TempDir("", "gotodo")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "todo.txt")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+
+	return gotodo.NewTodoManager(gotodo.WithFileStorage(path)), path
+}
+
+// runKeys runs the interface on a simulated screen, typing keys and then quitting
+func runKeys(t *testing.T, tm *gotodo.TodoManager, keys ...interface{}) tcell.SimulationScreen {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	assert.NoError(t, screen.Init())
+	screen.SetSize(80, 10)
+
+	go func() {
+		for _, key := range keys {
+			switch key := key.(type) {
+			case tcell.Key:
+				screen.PostEventWait(tcell.NewEventKey(key, 0, tcell.ModNone))
+			case string:
+				for _, r := range key {
+					screen.PostEventWait(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+				}
+			}
+		}
+		screen.PostEventWait(tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModNone))
+	}()
+
+	assert.NoError(t, run(tm, screen))
+	return screen
+}
+
+// screenText returns the text on every row of a simulated screen
+func screenText(screen tcell.SimulationScreen) []string {
+	cells, width, height := screen.GetContents()
+	rows := make([]string, height)
+	for y := 0; y < height; y++ {
+		var row strings.Builder
+		for x := 0; x < width; x++ {
+			row.WriteString(string(cells[y*width+x].Runes))
+		}
+		rows[y] = strings.TrimRight(row.String(), " ")
+	}
+
+	return rows
+}
+
+func readFile(t *testing.T, path string) string {
+	contents, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	return string(contents)
+}
+
+func TestRunList(t *testing.T) {
+	tm, _ := getTestManager(t, "Write docs due:2020-06-01\n(B) Fix bug\n(A) Ship release due:2020-05-01\n")
+
+	rows := screenText(runKeys(t, tm))
+	assert.Equal(t, "gotodo  3 todos  sorted by priority", strings.TrimSpace(rows[0]))
+	assert.Equal(t, "   3 (A) Ship release due:2020-05-01", rows[1])
+	assert.Equal(t, "   2 (B) Fix bug", rows[2])
+	assert.Equal(t, "   1 Write docs due:2020-06-01", rows[3])
+
+	// s re-sorts by due date
+	rows = screenText(runKeys(t, tm, "s"))
+	assert.Equal(t, "   3 (A) Ship release due:2020-05-01", rows[1])
+	assert.Equal(t, "   1 Write docs due:2020-06-01", rows[2])
+	assert.Equal(t, "Sorted by due date", rows[8])
+}
+
+func TestRunChanges(t *testing.T) {
+	tm, path := getTestManager(t, "(B) Fix bug\nWrite docs +docs\n")
+
+	// raise the priority of the second todo, complete the first and add a new one
+	runKeys(t, tm, tcell.KeyDown, "++", tcell.KeyUp, "x", "aPlan sprint @work", tcell.KeyEnter)
+	lines := strings.Split(readFile(t, path), "\n")
+	assert.Equal(t, true, strings.HasPrefix(lines[0], "x "))
+	assert.Equal(t, "(Y) Write docs +docs", lines[1])
+	assert.Equal(t, "Plan sprint @work", lines[2])
+
+	// edit inline and filter by project
+	screen := runKeys(t, tm, "/+docs", tcell.KeyEnter, "e", tcell.KeyBackspace2, tcell.KeyBackspace2, tcell.KeyBackspace2, tcell.KeyBackspace2, "web", tcell.KeyEnter)
+	assert.Equal(t, "(Y) Write docs +web", strings.Split(readFile(t, path), "\n")[1])
+	assert.Equal(t, "Updated Todo ID 2", screenText(screen)[8])
+}
+
+func TestPrioritySteps(t *testing.T) {
+	assert.Equal(t, 26, raisePriority(0))
+	assert.Equal(t, 1, raisePriority(2))
+	assert.Equal(t, 1, raisePriority(1))
+	assert.Equal(t, 0, lowerPriority(26))
+	assert.Equal(t, 3, lowerPriority(2))
+	assert.Equal(t, 0, lowerPriority(0))
+}